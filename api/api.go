@@ -0,0 +1,335 @@
+// Package api serves the engine as a JSON API over HTTP, for tools such as
+// browser userscripts and chat bots.
+//
+// Every endpoint takes a JSON object in a POST body and answers with a JSON
+// object, or with {"error": "..."} and a status saying what went wrong:
+// 400 for a bad request, 409 for a game that is already over and 503 if
+// the budget ran out before there was anything to answer with.
+// Positions use the schema documented in package game. Endpoints that
+// search take an optional "budget" (a Go duration such as "500ms") and
+// answer with what they found when it runs out:
+//
+//	POST /recommend {"game": <game>, "budget": "2s", "objective": "mean"}
+//	POST /score     {"roll": <roll>, "scorecard": <scorecard>}
+//	POST /value     {"game": <game>, "samples": 10000, "budget": "1s"}
+//	POST /value     {"scorecard": <scorecard>}
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/explain"
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/scoring"
+	"github.com/AustinJGreen/goyatzy/strategy"
+)
+
+// maxBodyBytes is the largest request body accepted.
+const maxBodyBytes = 1 << 20
+
+// defaultSamples is how many games /value plays when a request does not
+// say.
+const defaultSamples = 100000
+
+var (
+	// ErrBadRequest is returned for requests that are well formed JSON
+	// but cannot be answered.
+	ErrBadRequest = errors.New("bad request")
+	// ErrBudgetExhausted is returned when a search finds nothing to
+	// answer with in its budget.
+	ErrBudgetExhausted = errors.New("budget ran out")
+)
+
+// Server answers API requests. Create one with New.
+type Server struct {
+	// DefaultBudget is how long a search runs when the request does not
+	// give a budget.
+	DefaultBudget time.Duration
+	// MaxBudget caps the budget a request can ask for.
+	MaxBudget time.Duration
+	// NewSource returns the source of the dice rolled for a request.
+	NewSource func() *rand.PCG
+
+	mux *http.ServeMux
+}
+
+// New returns a server that searches for defaultBudget unless a request
+// asks for another budget, up to maxBudget.
+func New(defaultBudget, maxBudget time.Duration) *Server {
+	s := &Server{
+		DefaultBudget: defaultBudget,
+		MaxBudget:     maxBudget,
+		NewSource: func() *rand.PCG {
+			return rand.NewPCG(uint64(time.Now().UnixNano()), 0)
+		},
+		mux: http.NewServeMux(),
+	}
+	s.mux.HandleFunc("POST /recommend", s.handleRecommend)
+	s.mux.HandleFunc("POST /score", s.handleScore)
+	s.mux.HandleFunc("POST /value", s.handleValue)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// budget is a time budget given as a Go duration string.
+type budget time.Duration
+
+func (b *budget) UnmarshalText(text []byte) error {
+	d, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("%w: budget: %v", ErrBadRequest, err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("%w: budget %s must be positive", ErrBadRequest, d)
+	}
+	*b = budget(d)
+	return nil
+}
+
+// withBudget returns a context that is done when the request is cancelled
+// or its budget, capped at s.MaxBudget, runs out.
+func (s *Server) withBudget(ctx context.Context, b budget) (context.Context, context.CancelFunc) {
+	d := s.DefaultBudget
+	if b > 0 {
+		d = time.Duration(b)
+	}
+	return context.WithTimeout(ctx, min(d, s.MaxBudget))
+}
+
+// setup gives g the rng and players the server searches with: random
+// players in every seat.
+func (s *Server) setup(g *game.Game) {
+	g.Src = s.NewSource()
+	g.Rng = rand.New(g.Src)
+	g.Players = make([]game.Player, len(g.Scorecards))
+	for i := range g.Players {
+		g.Players[i] = &strategy.RandomPlayer{Rng: g.Rng}
+	}
+}
+
+type recommendRequest struct {
+	Game   *game.Game `json:"game"`
+	Budget budget     `json:"budget"`
+	// Objective is what to rank moves by (see strategy.ParseObjective).
+	Objective strategy.Objective `json:"objective"`
+}
+
+type recommendResponse struct {
+	Position string `json:"position"`
+	// Games is the number of playouts over every move.
+	Games uint64 `json:"games"`
+	// Moves are every legal move, best first, with why they rank where
+	// they do.
+	Moves []explain.Explanation `json:"moves"`
+}
+
+// handleRecommend ranks every move for the current player with the
+// monte-carlo player and explains the ranking.
+func (s *Server) handleRecommend(w http.ResponseWriter, r *http.Request) {
+	var req recommendRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	g := req.Game
+	switch {
+	case g == nil:
+		writeError(w, fmt.Errorf("%w: missing game", ErrBadRequest))
+		return
+	case g.IsOver():
+		writeError(w, game.ErrGameOver)
+		return
+	case g.CurTurn.RollCnt == 0:
+		writeError(w, fmt.Errorf("%w: nothing to decide until the dice are rolled", game.ErrNotRolled))
+		return
+	}
+	s.setup(g)
+
+	ctx, cancel := s.withBudget(r.Context(), req.Budget)
+	defer cancel()
+	mcp := &strategy.MonteCarloPlayer{Rng: g.Rng, Objective: req.Objective, Racing: strategy.DefaultRacing}
+	moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+	resp := recommendResponse{
+		Position: g.Notation(),
+		Moves:    explain.Explain(g, mcp.Rank(ctx, g, moves)),
+	}
+	for _, ms := range resp.Moves {
+		resp.Games += ms.Games
+	}
+	writeJSON(w, resp)
+}
+
+type scoreRequest struct {
+	Roll      dice.Roll          `json:"roll"`
+	Scorecard *scoring.Scorecard `json:"scorecard"`
+}
+
+type categoryScore struct {
+	Category scoring.Category `json:"category"`
+	Score    uint16           `json:"score"`
+}
+
+type scoreResponse struct {
+	Scores []categoryScore `json:"scores"`
+}
+
+// handleScore scores a roll in every category, or every open category of
+// a scorecard.
+func (s *Server) handleScore(w http.ResponseWriter, r *http.Request) {
+	var req scoreRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Roll == 0 {
+		writeError(w, fmt.Errorf("%w: missing roll", ErrBadRequest))
+		return
+	}
+	var ps scoring.Scorecard
+	if req.Scorecard != nil {
+		ps = *req.Scorecard
+	}
+	if ps.CatMask == scoring.AllFilled {
+		writeError(w, game.ErrGameOver)
+		return
+	}
+
+	// Selecting in a game scores the roll as the scorecard would,
+	// including any yatzy bonus.
+	g := &game.Game{
+		Scorecards: []scoring.Scorecard{ps},
+		CurTurn:    &game.Turn{CurrentRoll: req.Roll, RollCnt: scoring.MaxReRolls},
+	}
+	resp := scoreResponse{Scores: []categoryScore{}}
+	for _, m := range g.GetMovesForCurrentPlayer(req.Roll, nil) {
+		resp.Scores = append(resp.Scores, categoryScore{Category: m.Cat, Score: m.Score})
+	}
+	writeJSON(w, resp)
+}
+
+type valueRequest struct {
+	Game      *game.Game         `json:"game"`
+	Scorecard *scoring.Scorecard `json:"scorecard"`
+	Samples   int                `json:"samples"`
+	Budget    budget             `json:"budget"`
+}
+
+type quantiles struct {
+	P5  uint16 `json:"p5"`
+	P25 uint16 `json:"p25"`
+	P50 uint16 `json:"p50"`
+	P75 uint16 `json:"p75"`
+	P95 uint16 `json:"p95"`
+}
+
+type valueResponse struct {
+	Position string `json:"position"`
+	// Mean is the expected final score of the current player.
+	Mean      float64   `json:"mean"`
+	Quantiles quantiles `json:"quantiles"`
+	// Samples is the number of games played to estimate the value, or 0
+	// if it is exact.
+	Samples int `json:"samples"`
+}
+
+// handleValue estimates the final score of the current player of a game,
+// or of a lone scorecard at the start of a turn, when every player plays
+// randomly.
+func (s *Server) handleValue(w http.ResponseWriter, r *http.Request) {
+	var req valueRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	g := req.Game
+	switch {
+	case (g == nil) == (req.Scorecard == nil):
+		writeError(w, fmt.Errorf("%w: want exactly one of game and scorecard", ErrBadRequest))
+		return
+	case g == nil:
+		g = &game.Game{
+			Scorecards: []scoring.Scorecard{*req.Scorecard},
+			CurTurn:    &game.Turn{},
+		}
+	}
+	if g.IsOver() {
+		writeError(w, game.ErrGameOver)
+		return
+	}
+	if req.Samples < 0 {
+		writeError(w, fmt.Errorf("%w: samples %d must not be negative", ErrBadRequest, req.Samples))
+		return
+	}
+	samples := req.Samples
+	if samples == 0 {
+		samples = defaultSamples
+	}
+	s.setup(g)
+
+	ctx, cancel := s.withBudget(r.Context(), req.Budget)
+	defer cancel()
+	d := g.FinalScoreDistribution(ctx, samples)
+	if len(d.Scores) == 0 {
+		writeError(w, fmt.Errorf("%w before any game was played", ErrBudgetExhausted))
+		return
+	}
+	writeJSON(w, valueResponse{
+		Position: g.Notation(),
+		Mean:     d.Mean(),
+		Quantiles: quantiles{
+			P5:  d.Quantile(0.05),
+			P25: d.Quantile(0.25),
+			P50: d.Quantile(0.5),
+			P75: d.Quantile(0.75),
+			P95: d.Quantile(0.95),
+		},
+		Samples: d.Samples,
+	})
+}
+
+// decode decodes the body of r into v, writing an error response and
+// returning false if it cannot.
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, fmt.Errorf("%w: %w", ErrBadRequest, err))
+		return false
+	}
+	return true
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeError writes err as an error response, with a status for what
+// kind of error it is. Errors not known to be otherwise are the client's
+// fault.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, game.ErrGameOver):
+		status = http.StatusConflict
+	case errors.Is(err, ErrBudgetExhausted):
+		status = http.StatusServiceUnavailable
+	case errors.As(err, &tooLarge):
+		status = http.StatusRequestEntityTooLarge
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
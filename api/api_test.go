@@ -0,0 +1,180 @@
+package api
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newTestServer() *Server {
+	s := New(50*time.Millisecond, 200*time.Millisecond)
+	s.NewSource = func() *rand.PCG { return rand.NewPCG(1, 2) }
+	return s
+}
+
+// post sends body to path and decodes the response into v, returning the
+// status code.
+func post(t *testing.T, s *Server, path, body string, v any) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	if got := rec.Header().Get("Content-Type"); rec.Code != http.StatusNotFound && got != "application/json" {
+		t.Errorf("POST %s: Content-Type = %q; want application/json", path, got)
+	}
+	if v != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("POST %s: decoding %q: %v", path, rec.Body, err)
+		}
+	}
+	return rec.Code
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "every category",
+			body: `{"roll": [5, 5, 5, 2, 2]}`,
+			want: `{"scores": [
+				{"category": "ones", "score": 0},
+				{"category": "twos", "score": 4},
+				{"category": "threes", "score": 0},
+				{"category": "fours", "score": 0},
+				{"category": "fives", "score": 15},
+				{"category": "sixes", "score": 0},
+				{"category": "three of a kind", "score": 19},
+				{"category": "four of a kind", "score": 0},
+				{"category": "full house", "score": 25},
+				{"category": "small straight", "score": 0},
+				{"category": "large straight", "score": 0},
+				{"category": "chance", "score": 19},
+				{"category": "yatzy", "score": 0}
+			]}`,
+		},
+		{
+			name: "open categories",
+			body: `{"roll": [3, 3, 3, 3, 3], "scorecard": {"scores": {
+				"ones": 3, "twos": 6, "fours": 12, "fives": 15, "sixes": 18,
+				"three of a kind": 20, "four of a kind": 0, "full house": 25,
+				"small straight": 30, "large straight": 40, "chance": 22,
+				"yatzy": 50}}}`,
+			want: `{"scores": [{"category": "threes", "score": 15}]}`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got, want scoreResponse
+			if code := post(t, newTestServer(), "/score", tc.body, &got); code != http.StatusOK {
+				t.Fatalf("POST /score returned status %d; want %d", code, http.StatusOK)
+			}
+			if err := json.Unmarshal([]byte(tc.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(got, want); diff != "" {
+				t.Errorf("POST /score returned unexpected scores (-got, +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValue(t *testing.T) {
+	// Only chance is left, so the value is exact.
+	body := `{"scorecard": {"scores": {
+		"ones": 3, "twos": 6, "threes": 9, "fours": 12, "fives": 15, "sixes": 18,
+		"three of a kind": 20, "four of a kind": 0, "full house": 25,
+		"small straight": 30, "large straight": 40, "yatzy": 0}}}`
+	var got valueResponse
+	if code := post(t, newTestServer(), "/value", body, &got); code != http.StatusOK {
+		t.Fatalf("POST /value returned status %d; want %d", code, http.StatusOK)
+	}
+	if got.Samples != 0 {
+		t.Errorf("POST /value played %d samples; want an exact value", got.Samples)
+	}
+	if want := 178 + 35 + 17.5; got.Mean < want-1e-9 || got.Mean > want+1e-9 {
+		t.Errorf("POST /value mean = %v; want %v", got.Mean, want)
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	body := `{"game": {"scorecards": [{"scores": {}}, {"scores": {}}], "turn": {"roll": [6, 6, 6, 6, 1], "rollCount": 2}, "currentPlayer": 0}, "budget": "100ms", "objective": "mean"}`
+	var got recommendResponse
+	if code := post(t, newTestServer(), "/recommend", body, &got); code != http.StatusOK {
+		t.Fatalf("POST /recommend returned status %d; want %d", code, http.StatusOK)
+	}
+	if got.Position != "-/- 66661 2 1" {
+		t.Errorf("POST /recommend position = %q; want %q", got.Position, "-/- 66661 2 1")
+	}
+	// 13 categories and the 8 distinct ways to hold some of 6,6,6,6,1.
+	if len(got.Moves) != 21 {
+		t.Fatalf("POST /recommend returned %d moves; want 21", len(got.Moves))
+	}
+	var games uint64
+	for i, ms := range got.Moves {
+		games += ms.Games
+		// Moves dropped early rank after the rest, each by mean.
+		if i > 0 {
+			prev := got.Moves[i-1]
+			prevDropped, dropped := prev.Eliminated != "", ms.Eliminated != ""
+			if prevDropped && !dropped || prevDropped == dropped && ms.Mean > prev.Mean {
+				t.Errorf("move %d (%s) ranked below move %d (%s)", i-1, prev.Move, i, ms.Move)
+			}
+		}
+		if ms.Value != ms.Mean {
+			t.Errorf("move %d (%s) ranked by %v; want its mean %v", i, ms.Move, ms.Value, ms.Mean)
+		}
+		if !strings.HasPrefix(ms.Text, ms.Move.String()+": ") {
+			t.Errorf("move %d (%s) is explained as %q", i, ms.Move, ms.Text)
+		}
+	}
+	if games != got.Games || games == 0 {
+		t.Errorf("POST /recommend explored %d games over every move and %d in total; want the same positive number", games, got.Games)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+		want int
+	}{
+		{"not json", "/score", `{"roll":`, http.StatusBadRequest},
+		{"unknown field", "/score", `{"roll": [1, 2, 3, 4, 5], "dice": 5}`, http.StatusBadRequest},
+		{"missing roll", "/score", `{}`, http.StatusBadRequest},
+		{"invalid die", "/score", `{"roll": [1, 2, 3, 4, 7]}`, http.StatusBadRequest},
+		{"missing game", "/recommend", `{}`, http.StatusBadRequest},
+		{"not rolled", "/recommend", `{"game": {"scorecards": [{"scores": {}}], "turn": {"roll": null, "rollCount": 0}, "currentPlayer": 0}}`, http.StatusBadRequest},
+		{"bad budget", "/recommend", `{"game": {"scorecards": [{"scores": {}}], "turn": {"roll": [1, 2, 3, 4, 5], "rollCount": 1}, "currentPlayer": 0}, "budget": "soon"}`, http.StatusBadRequest},
+		{"bad objective", "/recommend", `{"game": {"scorecards": [{"scores": {}}], "turn": {"roll": [1, 2, 3, 4, 5], "rollCount": 1}, "currentPlayer": 0}, "objective": "quantile:2"}`, http.StatusBadRequest},
+		{"game and scorecard", "/value", `{"game": {"scorecards": [{"scores": {}}], "turn": {"roll": null, "rollCount": 0}, "currentPlayer": 0}, "scorecard": {"scores": {}}}`, http.StatusBadRequest},
+		{"negative samples", "/value", `{"scorecard": {"scores": {}}, "samples": -1}`, http.StatusBadRequest},
+		{"game over", "/value", `{"scorecard": {"scores": {"ones": 0, "twos": 0, "threes": 0, "fours": 0, "fives": 0, "sixes": 0, "three of a kind": 0, "four of a kind": 0, "full house": 0, "small straight": 0, "large straight": 0, "chance": 5, "yatzy": 0}}}`, http.StatusConflict},
+		{"no budget", "/value", `{"scorecard": {"scores": {}}, "budget": "1ns"}`, http.StatusServiceUnavailable},
+		{"too large", "/score", `{"roll": [1, 2, 3, 4, 5]` + strings.Repeat(" ", maxBodyBytes) + `}`, http.StatusRequestEntityTooLarge},
+		{"unknown endpoint", "/play", `{}`, http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got errorResponse
+			var v any = &got
+			if tc.want == http.StatusNotFound {
+				v = nil
+			}
+			if code := post(t, newTestServer(), tc.path, tc.body, v); code != tc.want {
+				t.Errorf("POST %s %s returned status %d; want %d", tc.path, tc.body, code, tc.want)
+			}
+			if v != nil && got.Error == "" {
+				t.Errorf("POST %s %s returned no error message", tc.path, tc.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,471 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/AustinJGreen/goyatzy/api"
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/explain"
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/render"
+	"github.com/AustinJGreen/goyatzy/scoring"
+	"github.com/AustinJGreen/goyatzy/server"
+	"github.com/AustinJGreen/goyatzy/strategy"
+	"github.com/AustinJGreen/goyatzy/tui"
+	"github.com/AustinJGreen/goyatzy/value"
+)
+
+func newSource() *rand.PCG {
+	return rand.NewPCG(uint64(time.Now().UnixNano()), 0)
+}
+
+// simCmd starts a new game between the given kinds of players.
+func simCmd(args []string) error {
+	fs := flag.NewFlagSet("sim", flag.ExitOnError)
+	players := fs.String("players", "random,mc", "comma separated kinds of player in turn order (one of "+playerKindNames()+")")
+	savePath := fs.String("save", "", "file to save the game to after every turn, so it can be resumed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	kinds := strings.Split(*players, ",")
+	g := game.New(newSource(), make([]game.Player, len(kinds)))
+	var err error
+	if g.Players, err = newPlayers(g, kinds); err != nil {
+		return err
+	}
+	return playGame(g, *savePath)
+}
+
+// playCmd plays a game at the terminal, with undo and redo.
+func playCmd(args []string) error {
+	fs := flag.NewFlagSet("play", flag.ExitOnError)
+	players := fs.String("players", "human,mc", "comma separated kinds of player in turn order (one of "+playerKindNames()+")")
+	manual := fs.Bool("manual", false, "enter every roll by hand instead of rolling dice")
+	savePath := fs.String("save", "", "file to save the game to after every turn, so it can be resumed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	kinds := strings.Split(*players, ",")
+	g := game.New(newSource(), make([]game.Player, len(kinds)))
+	var err error
+	if g.Players, err = newPlayers(g, kinds); err != nil {
+		return err
+	}
+	t := &terminal{
+		g:        g,
+		in:       stdin,
+		out:      os.Stdout,
+		manual:   *manual,
+		savePath: *savePath,
+	}
+	return t.run()
+}
+
+// resumeCmd resumes a game saved by sim -save.
+func resumeCmd(args []string) error {
+	fs := flag.NewFlagSet("resume", flag.ExitOnError)
+	savePath := fs.String("save", "", "file to save the game to after every turn (default is the resumed file)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: goyatzy resume [flags] <file>\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("resume takes exactly one saved game")
+	}
+
+	g, err := loadGame(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *savePath == "" {
+		*savePath = fs.Arg(0)
+	}
+	log.Printf("Resumed game at %s.", g.Notation())
+	return playGame(g, *savePath)
+}
+
+// stopGame handles DoPly failing with err. A human who stopped playing
+// is not an error: the game is saved where it was left, to be resumed.
+func stopGame(g *game.Game, savePath string, err error) error {
+	for _, p := range g.Players {
+		if hp, ok := p.(*humanPlayer); ok && hp.err != nil {
+			err = hp.err
+		}
+	}
+	if savePath != "" {
+		if serr := saveGame(g, savePath); serr != nil {
+			return errors.Join(err, fmt.Errorf("saving game: %w", serr))
+		}
+		log.Printf("Saved the game to %s.", savePath)
+	}
+	if errors.Is(err, errQuit) {
+		return nil
+	}
+	return err
+}
+
+// playGame plays g to the end, saving it to savePath (if set) after every
+// ply.
+func playGame(g *game.Game, savePath string) error {
+	// cardgames.io has human start first.
+	for !g.IsOver() {
+		gameOver, err := g.DoPly()
+		if err != nil {
+			return stopGame(g, savePath, err)
+		}
+		if savePath != "" {
+			if err := saveGame(g, savePath); err != nil {
+				return fmt.Errorf("saving game: %w", err)
+			}
+		}
+		if gameOver {
+			break
+		}
+		log.Println("Player finished turn.")
+	}
+	for i, ps := range g.Scorecards {
+		log.Printf("player [%s]: finished with %d points", g.Players[i], ps.Score())
+	}
+	return nil
+}
+
+// suggestCmd prints the move the monte-carlo player recommends for a
+// position given in position notation.
+func suggestCmd(args []string) error {
+	fs := flag.NewFlagSet("suggest", flag.ExitOnError)
+	think := fs.Duration("think", 10*time.Second, "how long to think for")
+	top := fs.Int("top", 5, "how many of the best moves to explain")
+	z := fs.Float64("z", strategy.DefaultRacing.Z, "drop moves this many standard errors behind the best early, to play out the rest more (0 plays out every move to the end)")
+	var objective strategy.Objective
+	fs.TextVar(&objective, "objective", strategy.Objective{}, "what to rank moves by: top:N (mean of the best N scores), mean, win, quantile:Q or risk:K (mean less K standard deviations)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: goyatzy suggest [flags] <position>\n\nexample: goyatzy suggest 'ss:30/- 55521 1 2'\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("suggest takes exactly one position")
+	}
+
+	g, err := game.ParsePosition(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if g.IsOver() {
+		return game.ErrGameOver
+	}
+	if g.CurTurn.RollCnt == 0 {
+		return fmt.Errorf("%w: nothing to decide until the dice are rolled", game.ErrNotRolled)
+	}
+
+	g.Src = newSource()
+	r := rand.New(g.Src)
+	g.Rng = r
+	mcp := &strategy.MonteCarloPlayer{Rng: r, Objective: objective, Racing: strategy.Racing{Z: *z}}
+	g.Players = make([]game.Player, len(g.Scorecards))
+	for i := range g.Players {
+		g.Players[i] = &strategy.RandomPlayer{Rng: r}
+	}
+	g.Players[g.CurPlayerIdx] = mcp
+
+	moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), *think)
+	defer cancel()
+	explained := explain.Explain(g, mcp.Rank(ctx, g, moves))
+	var games uint64
+	for _, e := range explained {
+		games += e.Games
+	}
+	fmt.Printf("position: %s\n", g.Notation())
+	fmt.Printf("recommended: %s\n", explained[0].Move)
+	fmt.Printf("best moves by %s after %d playouts:\n", objective, games)
+	for i, e := range explained[:min(*top, len(explained))] {
+		fmt.Printf("%d. %s\n   %s\n", i+1, e, e.MoveStats)
+	}
+	return nil
+}
+
+// oddsCmd prints the exact odds of scoring in each category from a roll.
+func oddsCmd(args []string) error {
+	fs := flag.NewFlagSet("odds", flag.ExitOnError)
+	holdArg := fs.String("hold", "", "dice to keep before rerolling (e.g. 2345)")
+	rollsLeft := fs.Int("rolls", scoring.MaxReRolls-1, "rolls left in the turn, including the reroll of the dice not held")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: goyatzy odds [flags] <dice>\n\nexample: goyatzy odds -hold 2345 -rolls 2 23455\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("odds takes exactly one roll")
+	}
+
+	rolled, err := parseDice(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if len(rolled) != 5 {
+		return fmt.Errorf("%w: rolled %d dice; want 5", dice.ErrInvalidHold, len(rolled))
+	}
+	held, err := parseDice(*holdArg)
+	if err != nil {
+		return err
+	}
+	if len(held) >= 5 {
+		return fmt.Errorf("%w: held %d dice; must leave at least one to reroll", dice.ErrInvalidHold, len(held))
+	}
+	r := dice.RollOf([5]dice.Die(rolled))
+	hold := dice.Count(held...)
+	odds, err := scoring.RollOdds(r, hold, *rollsLeft)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("rolled %s, holding %s with %d rolls left\n", r, holdString(hold), *rollsLeft)
+	for _, co := range odds {
+		var scores []string
+		for _, sp := range co.Scores {
+			scores = append(scores, fmt.Sprintf("%d:%.1f%%", sp.Score, 100*sp.P))
+		}
+		fmt.Printf("%-16s %6.2f%%  avg %5.2f  %s\n", co.Cat, 100*co.P, co.Mean(), strings.Join(scores, " "))
+	}
+	return nil
+}
+
+// distCmd prints the distribution of the final score of the current
+// player in a position given in position notation.
+func distCmd(args []string) error {
+	fs := flag.NewFlagSet("dist", flag.ExitOnError)
+	players := fs.String("players", "", "comma separated kinds of player in turn order (one of "+playerKindNames()+", default random for every player)")
+	samples := fs.Int("samples", 100000, "games to play if the distribution cannot be computed exactly")
+	think := fs.Duration("think", 100*time.Millisecond, "how long players that search, such as mc, think for each decision")
+	atLeast := fs.Int("at", 0, "also print the probability of scoring at least this much")
+	binWidth := fs.Int("bin", 10, "width of each bar of the histogram")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: goyatzy dist [flags] <position>\n\nexample: goyatzy dist -at 40 '1s:3,2s:6,3s:9,4s:12,5s:15,6s:18,3k:20,4k:0,fh:25,ss:30 - 0 1'\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("dist takes exactly one position")
+	}
+	if *binWidth <= 0 {
+		return fmt.Errorf("bin width %d must be positive", *binWidth)
+	}
+	if *samples <= 0 {
+		return fmt.Errorf("samples %d must be positive", *samples)
+	}
+	if *think <= 0 {
+		return fmt.Errorf("think time %s must be positive", *think)
+	}
+
+	g, err := game.ParsePosition(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	g.Src = newSource()
+	g.Rng = rand.New(g.Src)
+	kinds := make([]string, len(g.Scorecards))
+	for i := range kinds {
+		kinds[i] = "random"
+	}
+	if *players != "" {
+		kinds = strings.Split(*players, ",")
+		if len(kinds) != len(g.Scorecards) {
+			return fmt.Errorf("%d players for a position with %d scorecards", len(kinds), len(g.Scorecards))
+		}
+	}
+	if g.Players, err = newPlayers(g, kinds); err != nil {
+		return err
+	}
+	quiet(g.Players)
+	for i, p := range g.Players {
+		// Players whose moves can be enumerated do not think, and the
+		// distribution is only exact if they are not wrapped.
+		if _, ok := p.(game.MovePolicy); !ok {
+			g.Players[i] = &thinkingPlayer{Player: p, think: *think}
+		}
+	}
+
+	d := g.FinalScoreDistribution(context.Background(), *samples)
+	fmt.Printf("position: %s\n", g.Notation())
+	if d.Samples == 0 {
+		fmt.Println("exact distribution of the final score of player", g.CurPlayerIdx+1)
+	} else {
+		fmt.Printf("final score of player %d sampled from %d games\n", g.CurPlayerIdx+1, d.Samples)
+	}
+	fmt.Printf("mean: %.2f\n", d.Mean())
+	fmt.Printf("quantiles: 5%% %d, 25%% %d, 50%% %d, 75%% %d, 95%% %d\n",
+		d.Quantile(0.05), d.Quantile(0.25), d.Quantile(0.5), d.Quantile(0.75), d.Quantile(0.95))
+	if *atLeast > 0 {
+		fmt.Printf("P(score >= %d): %.2f%%\n", *atLeast, 100*d.AtLeast(uint16(*atLeast)))
+	}
+	return d.WriteHistogram(os.Stdout, uint16(*binWidth))
+}
+
+// thinkingPlayer gives every decision of a player that thinks until its
+// ctx is done, such as the monte-carlo player, a deadline.
+type thinkingPlayer struct {
+	game.Player
+	think time.Duration
+}
+
+func (p *thinkingPlayer) PickMove(ctx context.Context, g *game.Game, moves []game.Move) int {
+	ctx, cancel := context.WithTimeout(ctx, p.think)
+	defer cancel()
+	return p.Player.PickMove(ctx, g, moves)
+}
+
+// serveCmd serves the JSON API (see package api) until interrupted.
+func serveCmd(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	think := fs.Duration("think", 2*time.Second, "how long to think for when a request does not give a budget")
+	maxThink := fs.Duration("max-think", 30*time.Second, "the most a request can ask to think for")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return errors.New("serve takes no arguments")
+	}
+
+	log.Printf("Serving the API on http://%s.", *addr)
+	return http.ListenAndServe(*addr, api.New(*think, *maxThink))
+}
+
+// hostCmd hosts multiplayer games (see package server) until interrupted.
+func hostCmd(args []string) error {
+	fs := flag.NewFlagSet("host", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8081", "address to listen on")
+	turnTimeout := fs.Duration("turn-timeout", time.Minute, "how long a human has for each decision")
+	think := fs.Duration("think", 2*time.Second, "how long built-in players think for each decision")
+	maxTables := fs.Int("max-tables", server.DefaultMaxTables, "the most tables that can be open at once")
+	tableTTL := fs.Duration("table-ttl", server.DefaultTableTTL, "how long a table is kept after its game ends, or waits for its humans")
+	origins := fs.String("origins", "", "comma separated origins of web pages served elsewhere that may connect, such as https://example.com")
+	weights := fs.String("weights", "", "file of weights saved by train for value seats to play with (default built in)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return errors.New("host takes no arguments")
+	}
+
+	s := server.New(*turnTimeout, *think)
+	s.MaxTables = *maxTables
+	s.TableTTL = *tableTTL
+	if *origins != "" {
+		s.AllowedOrigins = strings.Split(*origins, ",")
+	}
+	if *weights != "" {
+		var err error
+		if s.Model, err = loadModel(*weights); err != nil {
+			return err
+		}
+	}
+	log.Printf("Hosting games on http://%s.", *addr)
+	return http.ListenAndServe(*addr, s)
+}
+
+// tuiCmd plays or watches a game full screen.
+func tuiCmd(args []string) error {
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	players := fs.String("players", "human,mc", "comma separated kinds of player in turn order (one of "+playerKindNames()+")")
+	think := fs.Duration("think", 2*time.Second, "how long to search for suggestions and bot moves")
+	delay := fs.Duration("delay", time.Second, "the least time each bot move is shown for")
+	ascii := fs.Bool("ascii", false, "draw the scorecards with plain ASCII instead of box drawing characters")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	kinds := strings.Split(*players, ",")
+	g := game.New(newSource(), make([]game.Player, len(kinds)))
+	var err error
+	if g.Players, err = newPlayers(g, kinds); err != nil {
+		return err
+	}
+	// Humans play at the keyboard of the tui rather than by typing moves.
+	for i, p := range g.Players {
+		if _, ok := p.(*humanPlayer); ok {
+			g.Players[i] = nil
+		}
+	}
+	// Bots log as they move, which would scroll the screen.
+	quiet(g.Players)
+	log.SetOutput(io.Discard)
+	opts := tui.Options{Think: *think, Delay: *delay, Style: render.Unicode}
+	if *ascii {
+		opts.Style = render.ASCII
+	}
+	return tui.Run(g, os.Stdin, os.Stdout, opts)
+}
+
+// trainCmd fits a value model (see package value) to games it plays
+// against itself and saves its weights.
+func trainCmd(args []string) error {
+	fs := flag.NewFlagSet("train", flag.ExitOnError)
+	games := fs.Int("games", 20000, "games to play each round")
+	rounds := fs.Int("rounds", 5, "rounds of playing and fitting; the first round is played at random")
+	ridge := fs.Float64("ridge", 1e-3, "how much to keep the weights small")
+	out := fs.String("out", "weights.json", "file to save the weights to")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *games <= 0 || *rounds <= 0 {
+		return fmt.Errorf("games %d and rounds %d must be positive", *games, *rounds)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	m, err := value.Train(ctx, rand.New(newSource()), value.TrainOptions{
+		Games:  *games,
+		Rounds: *rounds,
+		Ridge:  *ridge,
+		Progress: func(r value.Round) {
+			log.Printf("round %d: mean score %.2f over %d games, fit to %d turns with mean squared error %.1f",
+				r.Round+1, r.MeanScore, *games, r.Samples, r.MSE)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := m.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	log.Printf("Saved the weights to %s; play with them as player kind %s%s.", *out, trainedKindPrefix, *out)
+	return nil
+}
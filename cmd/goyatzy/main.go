@@ -0,0 +1,75 @@
+// Command goyatzy simulates, plays and analyzes games of yatzy.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+// yatzy (cardgames.io version not wikipedia yatzy, but somewhat confusingly the Yahtzee wikipedia game??)
+// components:
+// - rng
+// - dice
+// - probabilities
+// - terminology
+//   - upper section
+//     - ones, twos, threes, ...
+//     - 63 bonus - 35
+//   - lower section
+//     - three of a kind
+//     - four of a kind
+//     - full house
+//     - small straight
+//     - large straight
+//     - chance
+//     - yatzy
+// - simulation and CLI
+//
+// goal is to be able to simulate and measure perfromance
+// by average score and input rolls to play against bill.
+//
+// decisions to make in this game:
+// - what category to choose.
+// - whether you want to roll again.
+//
+// potential optimizations:
+// - faster rand (or different ones)
+// - pools for object allocation
+
+func main() {
+	log.SetFlags(0)
+
+	cmd, args := "sim", os.Args[1:]
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+	var err error
+	switch cmd {
+	case "sim":
+		err = simCmd(args)
+	case "play":
+		err = playCmd(args)
+	case "resume":
+		err = resumeCmd(args)
+	case "suggest":
+		err = suggestCmd(args)
+	case "odds":
+		err = oddsCmd(args)
+	case "dist":
+		err = distCmd(args)
+	case "serve":
+		err = serveCmd(args)
+	case "host":
+		err = hostCmd(args)
+	case "tui":
+		err = tuiCmd(args)
+	case "train":
+		err = trainCmd(args)
+	default:
+		err = fmt.Errorf("unknown command %q (want sim, play, resume, suggest, odds, dist, serve, host, tui or train)", cmd)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
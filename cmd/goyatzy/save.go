@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/solver"
+	"github.com/AustinJGreen/goyatzy/strategy"
+	"github.com/AustinJGreen/goyatzy/value"
+)
+
+// ErrInvalidSave is returned when loading a saved game that cannot be resumed.
+var ErrInvalidSave = errors.New("invalid saved game")
+
+// playerKinds creates each kind of player that can be saved and resumed by
+// name. Players share the rng of the game they are playing in. Bots show
+// how they decided on stdout (see quiet).
+var playerKinds = map[string]func(rng *rand.Rand) game.Player{
+	"random": func(rng *rand.Rand) game.Player { return &strategy.RandomPlayer{Rng: rng} },
+	"mc": func(rng *rand.Rand) game.Player {
+		return &strategy.MonteCarloPlayer{Rng: rng, Endgame: solver.Default, Racing: strategy.DefaultRacing, Out: os.Stdout}
+	},
+	"human": func(*rand.Rand) game.Player { return &humanPlayer{in: stdin, out: os.Stdout} },
+	"value": func(*rand.Rand) game.Player { return &value.Player{Model: value.Default()} },
+}
+
+// trainedKindPrefix is followed by the file of a model saved by train to
+// name a value player that plays with it, such as value:weights.json.
+const trainedKindPrefix = "value:"
+
+// trainedPlayer is a value player with a model loaded from path, which is
+// kept so it can be saved and resumed.
+type trainedPlayer struct {
+	*value.Player
+	path string
+}
+
+func (p *trainedPlayer) String() string {
+	return fmt.Sprintf("value player (%s)", filepath.Base(p.path))
+}
+
+// loadModel loads a model saved by train from path.
+func loadModel(path string) (*value.Model, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := value.Load(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+func playerKind(p game.Player) (string, error) {
+	switch p := p.(type) {
+	case *trainedPlayer:
+		return trainedKindPrefix + p.path, nil
+	case *strategy.RandomPlayer:
+		return "random", nil
+	case *strategy.MonteCarloPlayer:
+		return "mc", nil
+	case *humanPlayer:
+		return "human", nil
+	case *value.Player:
+		return "value", nil
+	}
+	return "", fmt.Errorf("player %T cannot be saved", p)
+}
+
+func playerKindNames() string {
+	var names []string
+	for name := range playerKinds {
+		names = append(names, name)
+	}
+	names = append(names, trainedKindPrefix+"<file>")
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+// newPlayers creates the players named by kinds for g.
+func newPlayers(g *game.Game, kinds []string) ([]game.Player, error) {
+	players := make([]game.Player, len(kinds))
+	for i, kind := range kinds {
+		if path, ok := strings.CutPrefix(kind, trainedKindPrefix); ok {
+			// The path is saved with the game, so it must not depend on
+			// where it is resumed from.
+			path, err := filepath.Abs(path)
+			if err != nil {
+				return nil, err
+			}
+			m, err := loadModel(path)
+			if err != nil {
+				return nil, err
+			}
+			players[i] = &trainedPlayer{Player: &value.Player{Model: m}, path: path}
+			continue
+		}
+		newPlayer, ok := playerKinds[kind]
+		if !ok {
+			return nil, fmt.Errorf("unknown player kind %q (want one of %s)", kind, playerKindNames())
+		}
+		players[i] = newPlayer(g.Rng)
+	}
+	return players, nil
+}
+
+// quiet stops players from showing how they decided, for commands whose
+// output it would drown.
+func quiet(players []game.Player) {
+	for _, p := range players {
+		if mcp, ok := p.(*strategy.MonteCarloPlayer); ok {
+			mcp.Out = nil
+		}
+	}
+}
+
+// savedGame is everything needed to resume a game exactly where it left off.
+type savedGame struct {
+	Game    *game.Game `json:"game"`
+	Players []string   `json:"players"`
+	RNG     []byte     `json:"rng"` // state of the game's PCG source.
+}
+
+// saveGame snapshots the full state of g to path. The file is replaced
+// atomically so an interrupted save never clobbers the previous snapshot.
+func saveGame(g *game.Game, path string) error {
+	sg := savedGame{Game: g}
+	for _, p := range g.Players {
+		kind, err := playerKind(p)
+		if err != nil {
+			return err
+		}
+		sg.Players = append(sg.Players, kind)
+	}
+	var err error
+	if sg.RNG, err = g.Src.MarshalBinary(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(sg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// loadGame resumes a game saved with saveGame.
+func loadGame(path string) (*game.Game, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sg savedGame
+	if err := json.Unmarshal(data, &sg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSave, err)
+	}
+	g := sg.Game
+	if g == nil {
+		return nil, fmt.Errorf("%w: no game", ErrInvalidSave)
+	}
+	if len(sg.Players) != len(g.Scorecards) {
+		return nil, fmt.Errorf("%w: %d players for %d scorecards", ErrInvalidSave, len(sg.Players), len(g.Scorecards))
+	}
+
+	g.Src = new(rand.PCG)
+	if err := g.Src.UnmarshalBinary(sg.RNG); err != nil {
+		return nil, fmt.Errorf("%w: rng: %v", ErrInvalidSave, err)
+	}
+	g.Rng = rand.New(g.Src)
+	if g.Players, err = newPlayers(g, sg.Players); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSave, err)
+	}
+	return g, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/scoring"
+	"github.com/AustinJGreen/goyatzy/solver"
+	"github.com/AustinJGreen/goyatzy/strategy"
+	"github.com/AustinJGreen/goyatzy/value"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGameSaveLoad(t *testing.T) {
+	opts := []cmp.Option{
+		cmp.AllowUnexported(game.Game{}, game.Turn{}, scoring.Scorecard{}, strategy.RandomPlayer{}, strategy.MonteCarloPlayer{}),
+		cmp.FilterPath(func(p cmp.Path) bool {
+			f, ok := p.Last().(cmp.StructField)
+			return ok && (f.Name() == "Rng" || f.Name() == "Src" || f.Name() == "moveBuf")
+		}, cmp.Ignore()),
+		// Players share solvers and stdout.
+		cmp.Comparer(func(a, b *solver.Solver) bool { return a == b }),
+		cmp.Comparer(func(a, b *os.File) bool { return a == b }),
+	}
+	g := game.New(rand.NewPCG(1, 2), make([]game.Player, 2))
+	g.Players = []game.Player{&strategy.RandomPlayer{Rng: g.Rng}, &strategy.MonteCarloPlayer{Rng: g.Rng, Endgame: solver.Default, Racing: strategy.DefaultRacing, Out: os.Stdout}}
+	for range 5 {
+		if g.CurTurn.RollCnt == 0 {
+			g.CurTurn.CurrentRoll = g.RandRoll()
+			g.CurTurn.RollCnt = 1
+		}
+		moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+		g.DoMove(moves[g.Rng.IntN(len(moves))])
+	}
+
+	path := filepath.Join(t.TempDir(), "game.json")
+	if err := saveGame(g, path); err != nil {
+		t.Fatalf("save() returned unexpected error: %v", err)
+	}
+	got, err := loadGame(path)
+	if err != nil {
+		t.Fatalf("loadGame() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(got, g, opts...); diff != "" {
+		t.Errorf("loaded game does not match (-got, +want):\n%s", diff)
+	}
+
+	// The resumed game must roll the same dice as the original would have.
+	for range 10 {
+		if got, want := got.RandRoll(), g.RandRoll(); got != want {
+			t.Fatalf("resumed game rolled %s; want %s", got, want)
+		}
+	}
+}
+
+// TestTrainedPlayerSaveLoad checks that a value player with weights loaded
+// from a file is resumed with the same weights.
+func TestTrainedPlayerSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	weights := filepath.Join(dir, "weights.json")
+	f, err := os.Create(weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := value.Default().Save(f); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	g := game.New(rand.NewPCG(1, 2), make([]game.Player, 1))
+	if g.Players, err = newPlayers(g, []string{trainedKindPrefix + weights}); err != nil {
+		t.Fatalf("newPlayers() returned unexpected error: %v", err)
+	}
+	path := filepath.Join(dir, "game.json")
+	if err := saveGame(g, path); err != nil {
+		t.Fatalf("save() returned unexpected error: %v", err)
+	}
+	got, err := loadGame(path)
+	if err != nil {
+		t.Fatalf("loadGame() returned unexpected error: %v", err)
+	}
+	p, ok := got.Players[0].(*trainedPlayer)
+	if !ok {
+		t.Fatalf("loaded player is %T; want *trainedPlayer", got.Players[0])
+	}
+	if p.path != weights {
+		t.Errorf("loaded player has weights %s; want %s", p.path, weights)
+	}
+	if diff := cmp.Diff(p.Model, value.Default(), cmp.AllowUnexported(value.Model{})); diff != "" {
+		t.Errorf("loaded weights do not match (-got, +want):\n%s", diff)
+	}
+}
+
+func TestLoadGameErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data string
+	}{
+		{"not json", `{`},
+		{"no game", `{"players":[],"rng":""}`},
+		{"player count", `{"game":{"scorecards":[{"scores":{}}],"turn":{"roll":null,"rollCount":0},"currentPlayer":0},"players":["random","mc"]}`},
+		{"player kind", `{"game":{"scorecards":[{"scores":{}}],"turn":{"roll":null,"rollCount":0},"currentPlayer":0},"players":["bill"],"rng":"cGNnOgAAAAAAAAABAAAAAAAAAAI="}`},
+		{"weights", `{"game":{"scorecards":[{"scores":{}}],"turn":{"roll":null,"rollCount":0},"currentPlayer":0},"players":["value:no-such-weights.json"],"rng":"cGNnOgAAAAAAAAABAAAAAAAAAAI="}`},
+		{"rng", `{"game":{"scorecards":[{"scores":{}}],"turn":{"roll":null,"rollCount":0},"currentPlayer":0},"players":["random"],"rng":""}`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "game.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := loadGame(path); !errors.Is(err, ErrInvalidSave) {
+				t.Errorf("loadGame() = %v; want %v", err, ErrInvalidSave)
+			}
+		})
+	}
+}
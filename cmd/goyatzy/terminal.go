@@ -0,0 +1,363 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/render"
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+// stdin is shared by everything reading from the terminal so that buffered
+// input is not lost between readers.
+var stdin = bufio.NewReader(os.Stdin)
+
+var errQuit = errors.New("quit")
+
+// parseDice parses dice written as digits, optionally separated by spaces
+// or commas (e.g. "555", "5 5 5" or "5,5,5").
+func parseDice(s string) ([]dice.Die, error) {
+	var ds []dice.Die
+	for _, r := range s {
+		switch {
+		case r >= '1' && r <= '6':
+			ds = append(ds, dice.Die(r-'0'))
+		case r == ' ' || r == ',':
+		default:
+			return nil, fmt.Errorf("%w: %q is not a die", dice.ErrInvalidHold, r)
+		}
+	}
+	return ds, nil
+}
+
+// parseCategoryArg parses a category by its position notation code (e.g.
+// "ss") or name (e.g. "small straight").
+func parseCategoryArg(s string) (scoring.Category, error) {
+	if c, ok := scoring.ParseCatCode(s); ok {
+		return c, nil
+	}
+	var c scoring.Category
+	if err := c.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("%w: unknown category %q", game.ErrInvalidSelection, s)
+	}
+	return c, nil
+}
+
+// parseMoveCommand parses "hold <dice>" or "select <category>" into a
+// validated move for the current player.
+func parseMoveCommand(g *game.Game, line string) (game.Move, error) {
+	cmd, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+	arg = strings.TrimSpace(arg)
+	var m game.Move
+	switch cmd {
+	case "hold", "h":
+		hold, err := parseDice(arg)
+		if err != nil {
+			return game.Move{}, err
+		}
+		if len(hold) > 5 {
+			return game.Move{}, fmt.Errorf("%w: held %d dice", dice.ErrInvalidHold, len(hold))
+		}
+		m = game.NewRerollMove(hold...)
+	case "select", "s":
+		c, err := parseCategoryArg(arg)
+		if err != nil {
+			return game.Move{}, err
+		}
+		m = g.SelectMove(c)
+	default:
+		return game.Move{}, fmt.Errorf("%w: %q (want hold <dice> or select <category>)", game.ErrUnknownMove, cmd)
+	}
+	if err := g.ValidateMove(m); err != nil {
+		return game.Move{}, err
+	}
+	return m, nil
+}
+
+// humanPlayer asks a person at the terminal for each move.
+type humanPlayer struct {
+	in  *bufio.Reader
+	out io.Writer
+	// err is why the human stopped playing, if they did: errQuit if they
+	// quit or their input ended. PickMove returns no move once it is set.
+	err error
+}
+
+func (hp *humanPlayer) String() string { return "human" }
+
+func (hp *humanPlayer) PickMove(_ context.Context, g *game.Game, moves []game.Move) int {
+	for {
+		fmt.Fprintf(hp.out, "rolled %s (roll %d/%d), hold <dice>, select <category> or quit: ", g.CurTurn.CurrentRoll, g.CurTurn.RollCnt, scoring.MaxReRolls)
+		line, err := hp.in.ReadString('\n')
+		switch {
+		case err != nil && line == "" && errors.Is(err, io.EOF):
+			hp.err = errQuit
+			return -1
+		case err != nil && line == "":
+			hp.err = fmt.Errorf("reading move: %w", err)
+			return -1
+		case strings.TrimSpace(line) == "quit":
+			hp.err = errQuit
+			return -1
+		}
+		m, err := parseMoveCommand(g, line)
+		if err != nil {
+			fmt.Fprintln(hp.out, err)
+			continue
+		}
+		if idx := slices.Index(moves, m); idx >= 0 {
+			return idx
+		}
+		fmt.Fprintf(hp.out, "%s is not one of the available moves\n", m)
+	}
+}
+
+const terminalHelp = `commands:
+  <dice>             enter rolled dice, e.g. 65431 (with -manual)
+  hold <dice>        reroll keeping dice, e.g. hold 5 5 5
+  select <category>  fill a category, by code (ss) or name (small straight)
+  moves              list the available moves
+  show               show the scorecards and position
+  undo               revert the last roll, hold or selection
+  redo               reapply the last undone action
+  quit               stop playing
+  <enter>            let a bot move after undo or redo`
+
+// terminal is an interactive play loop where human players type their
+// moves, and with manual set, every roll is entered by hand (e.g. when
+// playing against Bill on cardgames.io). Mistakes can be reverted with undo.
+type terminal struct {
+	g        *game.Game
+	in       *bufio.Reader
+	out      io.Writer
+	manual   bool
+	savePath string
+
+	// paused stops bots from moving after an undo or redo until the human
+	// continues, otherwise they would immediately redo what was undone.
+	paused bool
+	// rerolling is set with manual dice between a reroll being chosen and
+	// its dice being entered.
+	rerolling bool
+	hold      dice.Counts
+}
+
+func (t *terminal) isHuman() bool {
+	_, ok := t.g.Players[t.g.CurPlayerIdx].(*humanPlayer)
+	return ok
+}
+
+func (t *terminal) needsRoll() bool {
+	return t.g.CurTurn.RollCnt == 0 || t.rerolling
+}
+
+// run plays until the game is over or the human quits.
+func (t *terminal) run() error {
+	fmt.Fprintln(t.out, "type help for a list of commands")
+	for !t.g.IsOver() {
+		curPlayer := t.g.Players[t.g.CurPlayerIdx]
+		switch {
+		case t.needsRoll() && !t.manual:
+			r := t.g.RandRoll()
+			if t.rerolling {
+				r = t.g.RandRollWithKept(t.hold)
+			}
+			if err := t.roll(r); err != nil {
+				return err
+			}
+			continue
+		case !t.needsRoll() && !t.isHuman() && !t.paused:
+			moves := t.g.GetMovesForCurrentPlayer(t.g.CurTurn.CurrentRoll, nil)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			moveIdx := curPlayer.PickMove(ctx, t.g, moves)
+			cancel()
+			if moveIdx < 0 || moveIdx >= len(moves) {
+				return fmt.Errorf("player [%s]: %w: index %d of %d", curPlayer, game.ErrUnknownMove, moveIdx, len(moves))
+			}
+			if err := t.move(moves[moveIdx]); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch {
+		case t.needsRoll() && t.rerolling:
+			fmt.Fprintf(t.out, "player [%s]: enter dice rolled holding %s: ", curPlayer, holdString(t.hold))
+		case t.needsRoll():
+			fmt.Fprintf(t.out, "player [%s]: enter dice rolled: ", curPlayer)
+		case t.isHuman():
+			fmt.Fprintf(t.out, "player [%s]: rolled %s (roll %d/%d): ", curPlayer, t.g.CurTurn.CurrentRoll, t.g.CurTurn.RollCnt, scoring.MaxReRolls)
+		default:
+			fmt.Fprintf(t.out, "player [%s]: to move, press enter to continue: ", curPlayer)
+		}
+		line, err := t.in.ReadString('\n')
+		if err != nil && line == "" {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if err := t.exec(strings.TrimSpace(line)); err != nil {
+			if errors.Is(err, errQuit) {
+				return nil
+			}
+			fmt.Fprintln(t.out, err)
+		}
+	}
+
+	fmt.Fprintln(t.out, t.scorecards())
+	for i, ps := range t.g.Scorecards {
+		fmt.Fprintf(t.out, "player [%s]: finished with %d points\n", t.g.Players[i], ps.Score())
+	}
+	return nil
+}
+
+// scorecards draws every player's scorecard side by side.
+func (t *terminal) scorecards() string {
+	names := make([]string, len(t.g.Players))
+	for i, p := range t.g.Players {
+		names[i] = fmt.Sprint(p)
+	}
+	return render.Scorecards(render.Unicode, names, t.g.Scorecards)
+}
+
+// exec runs a single command typed by the human.
+func (t *terminal) exec(line string) error {
+	cmd, _, _ := strings.Cut(line, " ")
+	switch cmd {
+	case "":
+		t.paused = false
+		return nil
+	case "help":
+		fmt.Fprintln(t.out, terminalHelp)
+		return nil
+	case "quit":
+		return errQuit
+	case "show":
+		fmt.Fprintln(t.out, t.scorecards())
+		fmt.Fprintf(t.out, "position: %s\n", t.g.Notation())
+		return nil
+	case "moves":
+		if t.needsRoll() {
+			return game.ErrNotRolled
+		}
+		for _, m := range t.g.GetMovesForCurrentPlayer(t.g.CurTurn.CurrentRoll, nil) {
+			fmt.Fprintln(t.out, m)
+		}
+		return nil
+	case "undo":
+		t.paused = true
+		if t.rerolling {
+			t.rerolling = false
+			fmt.Fprintf(t.out, "undid holding %s\n", holdString(t.hold))
+			return nil
+		}
+		s, err := t.g.Undo()
+		if err != nil {
+			return err
+		}
+		if t.manual && s.Rolled && s.CurTurn.RollCnt > 0 {
+			// Undid the dice entered for a reroll, so enter them again.
+			t.rerolling = true
+			t.hold = s.Held
+			fmt.Fprintf(t.out, "undid dice rolled holding %s\n", holdString(t.hold))
+			return nil
+		}
+		fmt.Fprintf(t.out, "undid, now at %s\n", t.g.Notation())
+		return nil
+	case "redo":
+		t.paused = true
+		if err := t.g.Redo(); err != nil {
+			return err
+		}
+		t.rerolling = false
+		fmt.Fprintf(t.out, "redid, now at %s\n", t.g.Notation())
+		return nil
+	}
+
+	if t.needsRoll() {
+		if !t.manual {
+			return game.ErrNotRolled
+		}
+		rolled, err := parseDice(strings.TrimPrefix(line, "roll"))
+		if err != nil {
+			return err
+		}
+		if len(rolled) != 5 {
+			return fmt.Errorf("%w: entered %d dice; want 5", dice.ErrInvalidHold, len(rolled))
+		}
+		t.paused = false
+		return t.roll(dice.RollOf([5]dice.Die(rolled)))
+	}
+	if !t.isHuman() {
+		return fmt.Errorf("player [%s] is a bot, press enter to let it move", t.g.Players[t.g.CurPlayerIdx])
+	}
+	m, err := parseMoveCommand(t.g, line)
+	if err != nil {
+		return err
+	}
+	t.paused = false
+	return t.move(m)
+}
+
+// roll enters the dice rolled by the current player.
+func (t *terminal) roll(r dice.Roll) error {
+	var hold dice.Counts
+	if t.rerolling {
+		hold = t.hold
+	}
+	if err := t.g.EnterRoll(hold, r); err != nil {
+		return err
+	}
+	t.rerolling = false
+	fmt.Fprintf(t.out, "player [%s]: rolled %s\n", t.g.Players[t.g.CurPlayerIdx], r)
+	return nil
+}
+
+// move applies a legal move. With manual dice, rerolls wait for the new
+// dice to be entered.
+func (t *terminal) move(m game.Move) error {
+	pIdx := t.g.CurPlayerIdx
+	curPlayer := t.g.Players[pIdx]
+	if m.Reroll && t.manual {
+		if err := t.g.ValidateMove(m); err != nil {
+			return err
+		}
+		t.rerolling = true
+		t.hold = m.Hold
+		fmt.Fprintf(t.out, "player [%s]: %s\n", curPlayer, m)
+		return nil
+	}
+
+	if _, err := t.g.Play(m); err != nil {
+		return err
+	}
+	fmt.Fprintf(t.out, "player [%s]: %s\n", curPlayer, m)
+	if m.Reroll {
+		fmt.Fprintf(t.out, "player [%s]: rolled %s\n", curPlayer, t.g.CurTurn.CurrentRoll)
+		return nil
+	}
+	fmt.Fprintf(t.out, "player [%s]: has %d points\n", curPlayer, t.g.Scorecards[pIdx].Score())
+	if t.savePath != "" {
+		if err := saveGame(t.g, t.savePath); err != nil {
+			return fmt.Errorf("saving game: %w", err)
+		}
+	}
+	return nil
+}
+
+func holdString(hold dice.Counts) string {
+	if hold == 0 {
+		return "nothing"
+	}
+	return hold.String()
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/scoring"
+	"github.com/AustinJGreen/goyatzy/strategy"
+)
+
+func TestTerminalManualUndoRedo(t *testing.T) {
+	g := game.New(rand.NewPCG(5, 6), make([]game.Player, 2))
+	g.Players = []game.Player{&humanPlayer{}, &strategy.RandomPlayer{Rng: g.Rng}}
+	var out strings.Builder
+	term := &terminal{
+		g: g,
+		in: bufio.NewReader(strings.NewReader(strings.Join([]string{
+			"65431",
+			"hold 6 5 4 3",
+			"65433", // typo, meant 65432.
+			"undo",
+			"65432",
+			"undo",
+			"undo", // back to before holding.
+			"hold 6 5 4 3",
+			"65432",
+			"select ss", // meant large straight.
+			"undo",
+			"select xx",
+			"select ls",
+			"undo",
+			"redo",
+			"show",
+			"quit",
+		}, "\n"))),
+		out:    &out,
+		manual: true,
+	}
+	if err := term.run(); err != nil {
+		t.Fatalf("run() returned unexpected error: %v", err)
+	}
+
+	want := scoring.Scorecard{
+		ScoresByCategory: [13]uint16{scoring.CAT_LARGE_STRAIGHT: 40},
+		CatMask:          1 << scoring.CAT_LARGE_STRAIGHT,
+	}
+	if got := g.Scorecards[0]; got != want {
+		t.Errorf("scorecard after session:\n%s\nwant:\n%s", got.Pretty(), want.Pretty())
+	}
+	if got, want := g.Notation(), "ls:40/- - 0 2"; got != want {
+		t.Errorf("position after session = %q; want %q", got, want)
+	}
+	if !strings.Contains(out.String(), `unknown category "xx"`) {
+		t.Errorf("output does not report unknown category:\n%s", out.String())
+	}
+}
+
+func TestParseMoveCommand(t *testing.T) {
+	g := &game.Game{
+		Scorecards: []scoring.Scorecard{{}},
+		CurTurn: &game.Turn{
+			CurrentRoll: dice.RollOf([5]dice.Die{dice.DIE_SIX, dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_THREE, dice.DIE_ONE}),
+			RollCnt:     1,
+		},
+	}
+	moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+	for _, tt := range []struct {
+		line string
+		want string
+	}{
+		{"hold 5 5", "reroll holding five,five"},
+		{"h 55,6", "reroll holding six,five,five"},
+		{"select fives", "select fives for 10"},
+		{"s ch", "select chance for 20"},
+	} {
+		m, err := parseMoveCommand(g, tt.line)
+		if err != nil {
+			t.Errorf("parseMoveCommand(%q) returned unexpected error: %v", tt.line, err)
+			continue
+		}
+		idx := slices.Index(moves, m)
+		if idx < 0 {
+			t.Errorf("parseMoveCommand(%q) = %s; not an available move", tt.line, m)
+			continue
+		}
+		if got := moves[idx].String(); got != tt.want {
+			t.Errorf("parseMoveCommand(%q) = %s; want %s", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestPlayGameHumanQuits(t *testing.T) {
+	for _, input := range []string{"quit\n", ""} {
+		g := game.New(rand.NewPCG(5, 6), make([]game.Player, 2))
+		human := &humanPlayer{in: bufio.NewReader(strings.NewReader(input)), out: io.Discard}
+		g.Players = []game.Player{human, &strategy.RandomPlayer{Rng: g.Rng}}
+		path := filepath.Join(t.TempDir(), "game.json")
+		if err := playGame(g, path); err != nil {
+			t.Fatalf("playGame() with input %q returned unexpected error: %v", input, err)
+		}
+		if !errors.Is(human.err, errQuit) {
+			t.Errorf("human stopped with %v after input %q; want %v", human.err, input, errQuit)
+		}
+		saved, err := loadGame(path)
+		if err != nil {
+			t.Fatalf("loadGame() after quitting returned unexpected error: %v", err)
+		}
+		if got, want := saved.Notation(), g.Notation(); got != want {
+			t.Errorf("saved game is at %q; want %q where it was left", got, want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+// Package dice has the dice of yatzy: rolls, the multisets of dice that are
+// rolled and held, and the probabilities of rerolling them.
+package dice
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+)
+
+// Counts is a multiset of dice, packed as a 3 bit count for each face
+// (ones in the lowest bits). It is the canonical form of a roll or a hold:
+// any ordering of the same dice has the same Counts.
+type Counts uint32
+
+// invalidDice marks a multiset that had a die that is not one to six
+// added to it, so validating it fails rather than the die being lost.
+const invalidDice Counts = 1 << 31
+
+// tooManyDice marks a multiset that had more dice of a face added to it
+// than five dice can have, rather than its count carrying into the next
+// face.
+const tooManyDice Counts = 1 << 30
+
+// Count returns the multiset of dice.
+func Count(dice ...Die) Counts {
+	var dc Counts
+	for _, d := range dice {
+		dc = dc.Add(d)
+	}
+	return dc
+}
+
+// Count returns how many of dc are d.
+func (dc Counts) Count(d Die) int {
+	if !d.valid() {
+		return 0
+	}
+	return int(dc>>(3*(d-DIE_ONE))) & 7
+}
+
+// Add returns dc with d added. Adding a die that is not one to six, or a
+// sixth die of a face, marks dc as invalid (see ValidateHold).
+func (dc Counts) Add(d Die) Counts {
+	if !d.valid() {
+		return dc | invalidDice
+	}
+	if dc.Count(d) >= 5 {
+		return dc | tooManyDice
+	}
+	return dc + 1<<(3*(d-DIE_ONE))
+}
+
+// Len returns the number of dice.
+func (dc Counts) Len() int {
+	var n int
+	for d := DIE_ONE; d <= DIE_SIX; d++ {
+		n += dc.Count(d)
+	}
+	return n
+}
+
+// Contains reports whether every die in o is also in dc.
+func (dc Counts) Contains(o Counts) bool {
+	for d := DIE_ONE; d <= DIE_SIX; d++ {
+		if o.Count(d) > dc.Count(d) {
+			return false
+		}
+	}
+	return true
+}
+
+// Sub returns the dice in dc that are not in o. dc must contain o.
+func (dc Counts) Sub(o Counts) Counts {
+	// No face can borrow from the next since dc contains o.
+	return dc - o
+}
+
+// Dice returns the dice from highest to lowest.
+func (dc Counts) Dice() []Die {
+	var dice []Die
+	for d := DIE_SIX; d >= DIE_ONE; d-- {
+		for range dc.Count(d) {
+			dice = append(dice, d)
+		}
+	}
+	return dice
+}
+
+// Roll returns the canonical (ascending) roll of five dice.
+func (dc Counts) Roll() Roll {
+	var r [5]Die
+	var i int
+	for d := DIE_ONE; d <= DIE_SIX; d++ {
+		for range dc.Count(d) {
+			r[i] = d
+			i++
+		}
+	}
+	return RollOf(r)
+}
+
+func (dc Counts) String() string {
+	var names []string
+	for _, d := range dc.Dice() {
+		names = append(names, d.String())
+	}
+	return strings.Join(names, ",")
+}
+
+// maxCountsOrdinal bounds the ordinal of a multiset of at most five dice.
+const maxCountsOrdinal = 6 * 6 * 6 * 6 * 6 * 6
+
+// ordinal returns the counts of dc as a base 6 number, which is unique for
+// multisets of at most five dice.
+func (dc Counts) ordinal() int {
+	var ord int
+	for d := DIE_SIX; d >= DIE_ONE; d-- {
+		ord = ord*6 + dc.Count(d)
+	}
+	return ord
+}
+
+// DistinctHolds is the number of distinct multisets of zero to five dice.
+const DistinctHolds = 462
+
+// multisets is every multiset of zero to five dice, indexed by key. The
+// DistinctRolls multisets of five dice come first, so a roll's key is the
+// same as its key as a hold.
+var multisets, multisetKeys = buildMultisets()
+
+// Multiset returns the multiset of zero to five dice with key, which must
+// be less than DistinctHolds. Keys below DistinctRolls are the multisets
+// of five dice, so a roll's key is the same as its key as a hold.
+func Multiset(key int) Counts {
+	return multisets[key]
+}
+
+// Multisets returns every multiset of zero to five dice with its key, the
+// multisets of five dice first.
+func Multisets() iter.Seq2[int, Counts] {
+	return slices.All(multisets[:])
+}
+
+func buildMultisets() ([DistinctHolds]Counts, *[maxCountsOrdinal]uint16) {
+	var all []Counts
+	for ord := range maxCountsOrdinal {
+		var dc Counts
+		for d, rest := DIE_ONE, ord; d <= DIE_SIX; d, rest = d+1, rest/6 {
+			dc |= Counts(rest%6) << (3 * (d - DIE_ONE))
+		}
+		if dc.Len() <= 5 {
+			all = append(all, dc)
+		}
+	}
+	slices.SortStableFunc(all, func(a, b Counts) int {
+		return cmp.Compare(b.Len(), a.Len())
+	})
+
+	var sets [DistinctHolds]Counts
+	keys := new([maxCountsOrdinal]uint16)
+	for key, dc := range all {
+		sets[key] = dc
+		keys[dc.ordinal()] = uint16(key)
+	}
+	return sets, keys
+}
+
+// Key returns the key of dc (see Multiset).
+func (dc Counts) Key() int {
+	return int(multisetKeys[dc.ordinal()])
+}
+
+// factorials of the number of dice that can be rerolled.
+var factorials = [...]float64{1, 1, 2, 6, 24, 120}
+
+// RerollProbability returns the exact probability that rerolling every die
+// not in hold gives the dice in r, a multiset of five dice.
+func RerollProbability(hold, r Counts) float64 {
+	if r.Len() != 5 || !r.Contains(hold) {
+		return 0
+	}
+	rolled := r.Sub(hold)
+	n := rolled.Len()
+	// The multinomial coefficient counts the orders the dice can come in.
+	p := factorials[n]
+	for d := DIE_ONE; d <= DIE_SIX; d++ {
+		p /= factorials[rolled.Count(d)]
+	}
+	for range n {
+		p /= 6
+	}
+	return p
+}
+
+// Errors returned for dice that cannot be held. ErrEmptyHold,
+// ErrInvalidDie and ErrTooManyDice are returned wrapped with ErrInvalidHold.
+var (
+	ErrInvalidHold   = errors.New("invalid hold")
+	ErrHoldNotInRoll = errors.New("held dice are not in the current roll")
+	ErrEmptyHold     = errors.New("hold at least one die")
+	ErrInvalidDie    = errors.New("die is not one to six")
+	ErrTooManyDice   = errors.New("more dice than were rolled")
+)
+
+// ValidateHold checks that hold is a sub-multiset of r that keeps at least
+// one die and leaves at least one to reroll.
+func ValidateHold(r Roll, hold Counts) error {
+	if hold&invalidDice != 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidHold, ErrInvalidDie)
+	}
+	if hold&tooManyDice != 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidHold, ErrTooManyDice)
+	}
+	if hold == 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidHold, ErrEmptyHold)
+	}
+	if hold.Len() >= 5 {
+		return fmt.Errorf("%w: must leave at least one die to reroll", ErrInvalidHold)
+	}
+	rolled := r.Counts()
+	for d := DIE_ONE; d <= DIE_SIX; d++ {
+		if hold.Count(d) > rolled.Count(d) {
+			return fmt.Errorf("%w: %s (rolled %s)", ErrHoldNotInRoll, d, r)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package dice
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestDiceCountsRoll(t *testing.T) {
+	for _, r2 := range Rolls() {
+		dc := r2.Counts()
+		if got := dc.Len(); got != 5 {
+			t.Errorf("%s.counts().len() = %d; want 5", r2, got)
+		}
+		sorted := r2.Dice()
+		slices.Sort(sorted[:])
+		if got, want := dc.Roll(), RollOf(sorted); got != want {
+			t.Errorf("%s.counts().roll() = %s; want %s", r2, got, want)
+		}
+		if got := dc.Roll().Counts(); got != dc {
+			t.Errorf("%s.counts().roll().counts() = %s; want %s", r2, got, dc)
+		}
+		if got, want := dc.Key(), r2.Key(); got != want {
+			t.Errorf("%s.counts().key() = %d; want %d", r2, got, want)
+		}
+	}
+}
+
+func TestMultisets(t *testing.T) {
+	seen := make(map[Counts]bool)
+	for key, dc := range Multisets() {
+		if seen[dc] {
+			t.Errorf("multiset %s appears twice", dc)
+		}
+		seen[dc] = true
+		if got := dc.Key(); got != key {
+			t.Errorf("%s.key() = %d; want %d", dc, got, key)
+		}
+		if isRoll := key < DistinctRolls; isRoll != (dc.Len() == 5) {
+			t.Errorf("Multiset(%d) = %s has %d dice", key, dc, dc.Len())
+		}
+		if got := Count(dc.Dice()...); got != dc {
+			t.Errorf("Count(%s.dice()...) = %s", dc, got)
+		}
+	}
+}
+
+func TestDiceCountsContains(t *testing.T) {
+	for _, dc := range Multisets() {
+		for _, o := range Multisets() {
+			want := true
+			for d := DIE_ONE; d <= DIE_SIX; d++ {
+				want = want && o.Count(d) <= dc.Count(d)
+			}
+			if got := dc.Contains(o); got != want {
+				t.Fatalf("%s.contains(%s) = %t; want %t", dc, o, got, want)
+			}
+			if !want {
+				continue
+			}
+			rest := dc.Sub(o)
+			for d := DIE_ONE; d <= DIE_SIX; d++ {
+				if got, want := rest.Count(d), dc.Count(d)-o.Count(d); got != want {
+					t.Fatalf("%s.sub(%s).count(%s) = %d; want %d", dc, o, d, got, want)
+				}
+			}
+		}
+	}
+}
+
+// TestRerollProbability checks the probability of every roll from every
+// hold against counting every ordered reroll of the other dice.
+func TestRerollProbability(t *testing.T) {
+	for _, hold := range Multisets() {
+		n := 5 - hold.Len()
+		counts := make(map[Counts]int)
+		total := 1
+		for range n {
+			total *= 6
+		}
+		for ord := range total {
+			r := hold
+			for range n {
+				r = r.Add(Die(DIE_ONE + Die(ord%6)))
+				ord /= 6
+			}
+			counts[r]++
+		}
+
+		var sum float64
+		for _, r := range multisets[:DistinctRolls] {
+			got := RerollProbability(hold, r)
+			want := float64(counts[r]) / float64(total)
+			if math.Abs(got-want) > 1e-12 {
+				t.Errorf("RerollProbability(%s, %s) = %v; want %v", hold, r, got, want)
+			}
+			sum += got
+		}
+		if math.Abs(sum-1) > 1e-12 {
+			t.Errorf("probabilities of rolls holding %s sum to %v; want 1", hold, sum)
+		}
+	}
+}
+
+func TestValidateHold(t *testing.T) {
+	r := NewRoll(DIE_FIVE, DIE_FIVE, DIE_FIVE, DIE_TWO, DIE_ONE)
+	for _, tt := range []struct {
+		name string
+		hold Counts
+		want error
+	}{
+		{"some dice", Count(DIE_FIVE, DIE_TWO), nil},
+		{"no dice", Count(), ErrEmptyHold},
+		{"every die", r.Counts(), ErrInvalidHold},
+		{"die not rolled", Count(DIE_SIX), ErrHoldNotInRoll},
+		{"unset die", Count(DIE_FIVE, DIE_UNSET), ErrInvalidDie},
+		{"only an unset die", Count(DIE_UNSET), ErrInvalidDie},
+		{"eight ones", Count(DIE_ONE, DIE_ONE, DIE_ONE, DIE_ONE, DIE_ONE, DIE_ONE, DIE_ONE, DIE_ONE), ErrTooManyDice},
+		{"eight sixes", Count(DIE_SIX, DIE_SIX, DIE_SIX, DIE_SIX, DIE_SIX, DIE_SIX, DIE_SIX, DIE_SIX), ErrTooManyDice},
+	} {
+		if err := ValidateHold(r, tt.hold); !errors.Is(err, tt.want) || (err == nil) != (tt.want == nil) {
+			t.Errorf("ValidateHold() holding %s = %v; want %v", tt.name, err, tt.want)
+		}
+	}
+}
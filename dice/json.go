@@ -0,0 +1,60 @@
+package dice
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidJSON is returned when decoding JSON that is well formed but
+// does not describe a valid value.
+var ErrInvalidJSON = errors.New("invalid json value")
+
+// Values converts dice to ints so they are not encoded as base64 like
+// other byte slices.
+func Values(dice []Die) []int {
+	vals := make([]int, len(dice))
+	for i, d := range dice {
+		vals[i] = int(d)
+	}
+	return vals
+}
+
+func ParseValues(vals []int) ([]Die, error) {
+	dice := make([]Die, len(vals))
+	for i, v := range vals {
+		if v < int(DIE_ONE) || v > int(DIE_SIX) {
+			return nil, fmt.Errorf("%w: die value %d", ErrInvalidJSON, v)
+		}
+		dice[i] = Die(v)
+	}
+	return dice, nil
+}
+
+func (r2 Roll) MarshalJSON() ([]byte, error) {
+	if r2 == 0 {
+		return []byte("null"), nil
+	}
+	d := r2.Dice()
+	return json.Marshal(Values(d[:]))
+}
+
+func (r2 *Roll) UnmarshalJSON(data []byte) error {
+	var vals []int
+	if err := json.Unmarshal(data, &vals); err != nil {
+		return err
+	}
+	if vals == nil {
+		*r2 = 0
+		return nil
+	}
+	if len(vals) != 5 {
+		return fmt.Errorf("%w: roll has %d dice; want 5", ErrInvalidJSON, len(vals))
+	}
+	dice, err := ParseValues(vals)
+	if err != nil {
+		return err
+	}
+	*r2 = RollOf([5]Die(dice))
+	return nil
+}
@@ -0,0 +1,118 @@
+package dice
+
+import (
+	"iter"
+)
+
+// Outcome is a roll that can result from rerolling and its probability.
+type Outcome struct {
+	Roll Counts
+	Key  uint8 // Roll.Key of roll
+	P    float64
+}
+
+// rerollTable is the distribution over rolls from rerolling the dice not in
+// each hold, indexed by the hold's key.
+var rerollTable = buildRerollTable()
+
+// rollHolds is the key of every distinct hold that leaves at least one die
+// to reroll for each roll, indexed by Roll.Key.
+var rollHolds = buildRollHolds()
+
+// RollHolds returns the key of every distinct hold that leaves at least
+// one die to reroll from the roll with key.
+func RollHolds(key int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for _, h := range rollHolds[key] {
+			if !yield(int(h)) {
+				return
+			}
+		}
+	}
+}
+
+func buildRerollTable() *[DistinctHolds][]Outcome {
+	table := new([DistinctHolds][]Outcome)
+	for key, hold := range multisets {
+		for _, r := range multisets[:DistinctRolls] {
+			if p := RerollProbability(hold, r); p > 0 {
+				table[key] = append(table[key], Outcome{Roll: r, Key: uint8(r.Key()), P: p})
+			}
+		}
+	}
+	return table
+}
+
+func buildRollHolds() *[DistinctRolls][]uint16 {
+	holds := new([DistinctRolls][]uint16)
+	for key, r := range multisets[:DistinctRolls] {
+		dice := r.Dice()
+		seen := make(map[Counts]bool)
+		for mask := range 0b11111 {
+			var hold Counts
+			for idx, d := range dice {
+				if mask&(1<<idx) != 0 {
+					hold = hold.Add(d)
+				}
+			}
+			if !seen[hold] {
+				seen[hold] = true
+				holds[key] = append(holds[key], uint16(hold.Key()))
+			}
+		}
+	}
+	return holds
+}
+
+// RerollDistribution returns every roll that can result from rerolling
+// the dice not in hold, with its probability. The result must not be
+// modified.
+func RerollDistribution(hold Counts) []Outcome {
+	return rerollTable[hold.Key()]
+}
+
+// ExpectedValue returns the expected value of rerolling the dice not in
+// hold, given the value of every roll, indexed by Roll.Key.
+func ExpectedValue(values *[DistinctRolls]float64, hold Counts) float64 {
+	var ev float64
+	for _, o := range RerollDistribution(hold) {
+		ev += o.P * values[o.Key]
+	}
+	return ev
+}
+
+// Stages fills in the value of every roll of a turn with each number of
+// rerolls left, indexed by Roll.Key: stages[k] with k rerolls left, from
+// stages[0], the value of keeping each roll. Every roll is played for the
+// most value, keeping it or rerolling the dice not in one of its holds.
+// Rerolling every die is only played if rerollAll is set: it is not a
+// move (see ValidateHold), but it is part of the odds of the dice. expect
+// returns the value of rerolling the dice not in hold, given the value of
+// every roll after, and better reports whether a is worth more than b.
+func Stages[V any](stages [][DistinctRolls]V, rerollAll bool, expect func(values *[DistinctRolls]V, hold Counts) V, better func(a, b *V) bool) {
+	skip := -1
+	if !rerollAll {
+		skip = Counts(0).Key()
+	}
+	holds := new([DistinctHolds]V)
+	for k := 1; k < len(stages); k++ {
+		for key := DistinctRolls; key < DistinctHolds; key++ {
+			if key != skip {
+				holds[key] = expect(&stages[k-1], multisets[key])
+			}
+		}
+		for key := range stages[k] {
+			stages[k][key] = stages[0][key]
+			for _, h := range rollHolds[key] {
+				if int(h) != skip && better(&holds[h], &stages[k][key]) {
+					stages[k][key] = holds[h]
+				}
+			}
+		}
+	}
+}
+
+// MaxStages is Stages for values that are numbers, the higher the better.
+func MaxStages(stages [][DistinctRolls]float64, rerollAll bool) {
+	Stages(stages, rerollAll, ExpectedValue, func(a, b *float64) bool { return *a > *b })
+}
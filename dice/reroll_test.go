@@ -0,0 +1,48 @@
+package dice
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRerollDistribution(t *testing.T) {
+	for _, hold := range Multisets() {
+		var sum float64
+		for _, o := range RerollDistribution(hold) {
+			if !o.Roll.Contains(hold) {
+				t.Errorf("rerolling holding %s gives %s", hold, o.Roll)
+			}
+			if want := RerollProbability(hold, o.Roll); o.P != want {
+				t.Errorf("probability of %s holding %s = %v; want %v", o.Roll, hold, o.P, want)
+			}
+			sum += o.P
+		}
+		if math.Abs(sum-1) > 1e-12 {
+			t.Errorf("probabilities of rolls holding %s sum to %v; want 1", hold, sum)
+		}
+	}
+	if got := len(RerollDistribution(0)); got != DistinctRolls {
+		t.Errorf("rerolling every die gives %d rolls; want %d", got, DistinctRolls)
+	}
+}
+
+// TestMaxStages plays for sixes with one reroll from a roll without any:
+// rerolling every die expects 5/6 of a six, but keeping a die only 4/6.
+func TestMaxStages(t *testing.T) {
+	for _, tt := range []struct {
+		rerollAll bool
+		want      float64
+	}{
+		{true, 5.0 / 6},
+		{false, 4.0 / 6},
+	} {
+		stages := make([][DistinctRolls]float64, 2)
+		for key, r := range SortedRolls() {
+			stages[0][key] = float64(r.Counts().Count(DIE_SIX))
+		}
+		MaxStages(stages, tt.rerollAll)
+		if got := stages[1][NewRoll(1, 2, 3, 4, 5).Key()]; math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("MaxStages(rerollAll %t) values 12345 at %v; want %v", tt.rerollAll, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package dice
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+)
+
+// Die is the value of a die, from DIE_ONE to DIE_SIX.
+type Die byte
+
+const (
+	DIE_UNSET Die = iota
+	DIE_ONE
+	DIE_TWO
+	DIE_THREE
+	DIE_FOUR
+	DIE_FIVE
+	DIE_SIX
+)
+
+func (d Die) String() string {
+	return [...]string{
+		"unset",
+		"one",
+		"two",
+		"three",
+		"four",
+		"five",
+		"six",
+	}[d]
+}
+
+// valid reports whether d is one of the six faces.
+func (d Die) valid() bool {
+	return d >= DIE_ONE && d <= DIE_SIX
+}
+
+// might want to consider also iterating on this and just doing rand on some entire slice.
+// var rollToHash map[roll]uint16
+// var rollHashScores map[uint16][13]uint16
+
+// Roll is five dice in the order they were rolled, packed 3 bits each.
+type Roll uint16
+
+func NewRoll(a, b, c, d, e Die) Roll {
+	return Roll(uint16(a&7) | uint16(b&7)<<3 | uint16(c&7)<<6 | uint16(d&7)<<9 | uint16(e&7)<<12)
+}
+
+// RollOf returns the roll of d.
+func RollOf(d [5]Die) Roll {
+	return NewRoll(d[0], d[1], d[2], d[3], d[4])
+}
+
+func (r2 Roll) Die(idx int) Die {
+	return Die((r2 >> (idx * 3)) & 7)
+}
+
+func (r2 Roll) Dice() [5]Die {
+	a := Die(r2 & 7)
+	b := Die((r2 >> 3) & 7)
+	c := Die((r2 >> 6) & 7)
+	d := Die((r2 >> 9) & 7)
+	e := Die((r2 >> 12) & 7)
+	return [5]Die{a, b, c, d, e}
+}
+
+func (r2 Roll) Counts() Counts {
+	d := r2.Dice()
+	return Count(d[:]...)
+}
+
+func (r2 Roll) String() string {
+	a := Die(r2 & 7)
+	b := Die((r2 >> 3) & 7)
+	c := Die((r2 >> 6) & 7)
+	d := Die((r2 >> 9) & 7)
+	e := Die((r2 >> 12) & 7)
+	return fmt.Sprintf("%s,%s,%s,%s,%s", a, b, c, d, e)
+}
+
+// Notation returns the dice in roll order as digits, e.g. 55521.
+func (r2 Roll) Notation() string {
+	var bldr strings.Builder
+	for _, d := range r2.Dice() {
+		bldr.WriteByte('0' + byte(d))
+	}
+	return bldr.String()
+}
+
+// OrderedRolls is the number of ordered rolls of five dice.
+const OrderedRolls = 6 * 6 * 6 * 6 * 6
+
+// rolls is every ordered roll, indexed by Roll.Ordinal.
+var rolls [OrderedRolls]Roll
+
+// RollAt returns the ordered roll with ordinal ord, which must be less
+// than OrderedRolls.
+func RollAt(ord int) Roll {
+	return rolls[ord]
+}
+
+// Rolls returns every ordered roll with its ordinal.
+func Rolls() iter.Seq2[int, Roll] {
+	return slices.All(rolls[:])
+}
+
+// DistinctRolls is the number of distinct multisets of five dice.
+const DistinctRolls = 252
+
+// sortedRolls is the canonical (ascending) roll of every distinct multiset
+// of five dice, indexed by Roll.Key (the multiset's key).
+var sortedRolls [DistinctRolls]Roll
+
+// SortedRolls returns the canonical (ascending) roll of every distinct
+// multiset of five dice with its key.
+func SortedRolls() iter.Seq2[int, Roll] {
+	return slices.All(sortedRolls[:])
+}
+
+// rollKeys maps every ordered roll to the index of its multiset in sortedRolls.
+var rollKeys [1 << 15]uint8
+
+// Key returns the key of the multiset of r2, which is also its key as a
+// hold (see Counts.Key).
+func (r2 Roll) Key() int {
+	return int(rollKeys[r2])
+}
+
+// Ordinal returns the index of an ordered roll in Rolls.
+func (r2 Roll) Ordinal() int {
+	var ord int
+	for _, d := range r2.Dice() {
+		ord = ord*6 + int(d-DIE_ONE)
+	}
+	return ord
+}
+
+func getDiceCombos(n int) [][]Die {
+	if n == 0 {
+		return nil
+	} else if n == 1 {
+		return [][]Die{{1}, {2}, {3}, {4}, {5}, {6}}
+	}
+
+	var next [][]Die
+	left := getDiceCombos(n - 1)
+	for i := range 6 {
+		d := Die(i + 1)
+		for _, l := range left {
+			next = append(next, append([]Die{d}, l...))
+		}
+	}
+	return next
+}
+
+func init() {
+	for key, dc := range multisets[:DistinctRolls] {
+		sortedRolls[key] = dc.Roll()
+	}
+	for ord, combos := range getDiceCombos(5) {
+		r2 := NewRoll(combos[0], combos[1], combos[2], combos[3], combos[4])
+		rolls[ord] = r2
+		rollKeys[r2] = uint8(r2.Counts().Key())
+	}
+}
@@ -0,0 +1,168 @@
+// Package explain says why moves are recommended: how each ranks against
+// the best, which categories it plays for and how likely they are to
+// score, and what it does to the chance of the upper section bonus.
+package explain
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/scoring"
+	"github.com/AustinJGreen/goyatzy/strategy"
+)
+
+// maxTargets is how many categories a reroll is said to play for.
+const maxTargets = 3
+
+// Target is a category a move plays for.
+type Target struct {
+	Cat scoring.Category `json:"category"`
+	// P is the probability of scoring in Cat by the end of the turn,
+	// playing for it.
+	P float64 `json:"p"`
+	// Mean is the mean score of Cat by the end of the turn, playing for
+	// it.
+	Mean float64 `json:"mean"`
+}
+
+// Explanation is a ranked move and the reasons for its rank.
+type Explanation struct {
+	strategy.MoveStats
+	// Gap is how far the mean final score of the move is below the best
+	// move's, negative if it is above.
+	Gap float64 `json:"gap"`
+	// Targets are the categories the move plays for, most helped first.
+	// Selecting plays for the category selected.
+	Targets []Target `json:"targets"`
+	// BonusBefore and BonusAfter are the probability of earning the upper
+	// section bonus, playing for it, before and after the move.
+	BonusBefore float64 `json:"bonusBefore"`
+	BonusAfter  float64 `json:"bonusAfter"`
+	// Text is all of the above in a line.
+	Text string `json:"text"`
+}
+
+// Explain explains every move of ranked, which ranks the moves of the
+// current player of g best first.
+func Explain(g *game.Game, ranked []strategy.MoveStats) []Explanation {
+	if len(ranked) == 0 {
+		return nil
+	}
+	t := g.CurTurn
+	ps := g.Scorecards[g.CurPlayerIdx]
+	bonusBefore := scoring.TurnBonusProbability(ps, t.CurrentRoll, t.RollCnt)
+
+	// A reroll plays for the categories it makes score more than
+	// rerolling every die would.
+	var base [scoring.Categories]scoring.CategoryOdds
+	rollsLeft := scoring.MaxReRolls - t.RollCnt
+	if rollsLeft > 0 {
+		base, _ = scoring.RollOdds(t.CurrentRoll, 0, rollsLeft)
+	}
+
+	explained := make([]Explanation, len(ranked))
+	for i, ms := range ranked {
+		e := Explanation{
+			MoveStats:   ms,
+			Gap:         ranked[0].Mean - ms.Mean,
+			BonusBefore: bonusBefore,
+			BonusAfter:  g.MoveBonusProbability(ms.Move),
+		}
+		if ms.Move.Reroll {
+			e.Targets = rerollTargets(ps, t.CurrentRoll, ms.Move.Hold, rollsLeft, &base)
+		} else {
+			var p float64
+			if ms.Move.Score > 0 {
+				p = 1
+			}
+			e.Targets = []Target{{Cat: ms.Move.Cat, P: p, Mean: float64(ms.Move.Score)}}
+		}
+		e.Text = e.text(i == 0)
+		explained[i] = e
+	}
+	return explained
+}
+
+// rerollTargets returns the open categories of ps that holding hold of r
+// helps most, compared with base, the odds of rerolling every die.
+func rerollTargets(ps scoring.Scorecard, r dice.Roll, hold dice.Counts, rollsLeft int, base *[scoring.Categories]scoring.CategoryOdds) []Target {
+	odds, err := scoring.RollOdds(r, hold, rollsLeft)
+	if err != nil {
+		return nil
+	}
+	type lifted struct {
+		Target
+		lift float64
+	}
+	var helped []lifted
+	for c, co := range odds {
+		if ps.CatMask&(1<<c) != 0 {
+			continue
+		}
+		mean := co.Mean()
+		if lift := mean - base[c].Mean(); lift > 0.05 {
+			helped = append(helped, lifted{Target{Cat: co.Cat, P: co.P, Mean: mean}, lift})
+		}
+	}
+	slices.SortStableFunc(helped, func(a, b lifted) int {
+		switch {
+		case a.lift > b.lift:
+			return -1
+		case a.lift < b.lift:
+			return 1
+		}
+		return 0
+	})
+	var targets []Target
+	for _, l := range helped[:min(maxTargets, len(helped))] {
+		targets = append(targets, l.Target)
+	}
+	return targets
+}
+
+func (e Explanation) String() string {
+	return e.Text
+}
+
+// text says in a line what e is made of. best is whether e is the best
+// move.
+func (e Explanation) text(best bool) string {
+	parts := []string{fmt.Sprintf("%s: %.1f final points expected", e.Move, e.Mean)}
+	switch {
+	case best:
+		parts[0] += ", the best"
+	case math.Abs(e.Gap) < 0.05:
+		parts[0] += ", as much as the best"
+	case e.Gap > 0:
+		parts[0] += fmt.Sprintf(", %.1f less than the best", e.Gap)
+	default:
+		parts[0] += fmt.Sprintf(", %.1f more than the best but ranked lower", -e.Gap)
+	}
+
+	switch {
+	case !e.Move.Reroll && e.Move.Score == 0:
+		parts = append(parts, fmt.Sprintf("scratches %s", e.Move.Cat))
+	case !e.Move.Reroll:
+		parts = append(parts, fmt.Sprintf("scores %d in %s", e.Move.Score, e.Move.Cat))
+	case len(e.Targets) > 0:
+		var aims []string
+		for _, t := range e.Targets {
+			aims = append(aims, fmt.Sprintf("%s %.0f%% (%.1f avg)", t.Cat, 100*t.P, t.Mean))
+		}
+		parts = append(parts, "plays for "+strings.Join(aims, ", "))
+	}
+
+	switch before, after := e.BonusBefore, e.BonusAfter; {
+	case before == after && (before == 0 || before == 1):
+		// The bonus is decided either way.
+	case math.Abs(after-before) < 0.0005:
+		parts = append(parts, fmt.Sprintf("upper bonus stays at %.1f%%", 100*after))
+	default:
+		parts = append(parts, fmt.Sprintf("upper bonus %.1f%% -> %.1f%%", 100*before, 100*after))
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,78 @@
+package explain
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/scoring"
+	"github.com/AustinJGreen/goyatzy/strategy"
+)
+
+func TestExplain(t *testing.T) {
+	g, err := game.ParsePosition("- 55521 1 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ranked := []strategy.MoveStats{
+		{Move: g.SelectMove(scoring.CAT_THREE_OF_A_KIND), Mean: 60},
+		{Move: game.NewRerollMove(dice.DIE_FIVE, dice.DIE_FIVE), Mean: 50},
+		{Move: g.SelectMove(scoring.CAT_YATZY), Mean: 62},
+	}
+	got := Explain(g, ranked)
+	if len(got) != len(ranked) {
+		t.Fatalf("Explain() returned %d explanations; want %d", len(got), len(ranked))
+	}
+
+	before := scoring.TurnBonusProbability(g.Scorecards[0], g.CurTurn.CurrentRoll, 1)
+	for i, tt := range []struct {
+		gap     float64
+		targets []scoring.Category
+		text    []string
+	}{
+		{
+			gap:     0,
+			targets: []scoring.Category{scoring.CAT_THREE_OF_A_KIND},
+			text:    []string{"select three of a kind for 18: 60.0 final points expected, the best", "scores 18 in three of a kind", "upper bonus"},
+		},
+		{
+			gap:     10,
+			targets: []scoring.Category{scoring.CAT_FIVES},
+			text:    []string{"10.0 less than the best", "plays for ", "fives 100%"},
+		},
+		{
+			gap:     -2,
+			targets: []scoring.Category{scoring.CAT_YATZY},
+			text:    []string{"2.0 more than the best but ranked lower", "scratches yatzy"},
+		},
+	} {
+		e := got[i]
+		if e.Gap != tt.gap {
+			t.Errorf("%s: Gap = %v; want %v", e.Move, e.Gap, tt.gap)
+		}
+		var cats []scoring.Category
+		for _, target := range e.Targets {
+			cats = append(cats, target.Cat)
+		}
+		for _, c := range tt.targets {
+			if !slices.Contains(cats, c) {
+				t.Errorf("%s: Targets = %v; want %s among them", e.Move, e.Targets, c)
+			}
+		}
+		if e.BonusBefore != before || e.BonusAfter != g.MoveBonusProbability(e.Move) {
+			t.Errorf("%s: upper bonus %v -> %v; want %v -> %v", e.Move, e.BonusBefore, e.BonusAfter, before, g.MoveBonusProbability(e.Move))
+		}
+		for _, want := range tt.text {
+			if !strings.Contains(e.Text, want) {
+				t.Errorf("%s: Text = %q; want it to contain %q", e.Move, e.Text, want)
+			}
+		}
+	}
+
+	// Holding two fives helps fives more than anything else.
+	if reroll := got[1]; reroll.Targets[0].Cat != scoring.CAT_FIVES || reroll.Targets[0].P != 1 {
+		t.Errorf("%s: first target = %+v; want fives certain to score", reroll.Move, reroll.Targets[0])
+	}
+}
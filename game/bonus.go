@@ -0,0 +1,55 @@
+package game
+
+import (
+	"context"
+	"math"
+
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+// MoveBonusProbability returns the exact probability that the current
+// player earns the upper section bonus after m, playing for it.
+func (g *Game) MoveBonusProbability(m Move) float64 {
+	ps := g.Scorecards[g.CurPlayerIdx]
+	if !m.Reroll {
+		return scoring.BonusProbability(ps.Update(g.CurTurn.CurrentRoll, m.Cat))
+	}
+	return scoring.HoldBonusProbability(ps, m.Hold, g.CurTurn.RollCnt)
+}
+
+// SampleBonusProbability estimates the probability that the current
+// player earns the upper section bonus when every player plays as g's
+// players do, from n playouts. It returns the estimate and its standard
+// error, stopping early if ctx is done. Only playouts that finished count.
+func (g *Game) SampleBonusProbability(ctx context.Context, n int) (p, stderr float64) {
+	pIdx := g.CurPlayerIdx
+	var earned, played int
+	for range n {
+		if ctx.Err() != nil {
+			break
+		}
+		sg := g.Clone()
+		if sg.CurTurn.RollCnt > 0 {
+			// Finish the turn underway before starting new ones.
+			for sg.CurTurn.RollCnt > 0 && !sg.IsOver() {
+				moves := sg.GetMovesForCurrentPlayer(sg.CurTurn.CurrentRoll, sg.moveBuf[:0])
+				sg.DoMove(moves[sg.Players[pIdx].PickMove(ctx, sg, moves)])
+			}
+		}
+		if !sg.IsOver() {
+			sg.RunSimulation(ctx)
+		}
+		if !sg.IsOver() {
+			break // cut short by ctx, so it says nothing about the bonus.
+		}
+		played++
+		if sg.Scorecards[pIdx].UpperSum() >= scoring.UpperSectionMinBonusSum {
+			earned++
+		}
+	}
+	if played == 0 {
+		return 0, 0
+	}
+	p = float64(earned) / float64(played)
+	return p, math.Sqrt(p * (1 - p) / float64(played))
+}
@@ -0,0 +1,67 @@
+package game
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+// upperCard returns a scorecard with every category filled except open,
+// and upper section total sum.
+func upperCard(sum uint16, open ...scoring.Category) scoring.Scorecard {
+	ps := scoring.Scorecard{CatMask: scoring.AllFilled}
+	ps.ScoresByCategory[scoring.CAT_ONES] = sum
+	for _, c := range open {
+		ps.CatMask &^= 1 << c
+	}
+	return ps
+}
+
+func TestMoveBonusProbability(t *testing.T) {
+	ps := upperCard(39, scoring.CAT_SIXES)
+	// The turn's chance is the chance of the best move.
+	g := &Game{
+		Scorecards: []scoring.Scorecard{ps},
+		CurTurn:    &Turn{CurrentRoll: dice.RollOf([5]dice.Die{dice.DIE_SIX, dice.DIE_SIX, dice.DIE_TWO, dice.DIE_ONE, dice.DIE_ONE}), RollCnt: 1},
+	}
+	var best float64
+	for _, m := range g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil) {
+		best = max(best, g.MoveBonusProbability(m))
+	}
+	if want := scoring.TurnBonusProbability(ps, g.CurTurn.CurrentRoll, 1); math.Abs(best-want) > 1e-12 {
+		t.Errorf("best move's bonus probability = %v; want %v", best, want)
+	}
+}
+
+func TestSampleBonusProbability(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name string
+		ps   scoring.Scorecard
+		want float64
+	}{
+		{"already earned", upperCard(63, scoring.CAT_SIXES, scoring.CAT_CHANCE), 1},
+		{"out of reach", upperCard(30, scoring.CAT_SIXES, scoring.CAT_CHANCE), 0},
+	} {
+		g := New(rand.NewPCG(1, 2), make([]Player, 1))
+		g.Players[0] = &randomPlayer{g.Rng}
+		g.Scorecards[0] = tt.ps
+		if p, stderr := g.SampleBonusProbability(ctx, 100); p != tt.want || stderr != 0 {
+			t.Errorf("%s: SampleBonusProbability() = %v ± %v; want %v", tt.name, p, stderr, tt.want)
+		}
+	}
+
+	// Random play rarely earns the bonus, even when it is likely playing
+	// for it.
+	g := New(rand.NewPCG(1, 2), make([]Player, 1))
+	g.Players[0] = &randomPlayer{g.Rng}
+	g.Scorecards[0] = upperCard(39, scoring.CAT_SIXES, scoring.CAT_CHANCE)
+	p, stderr := g.SampleBonusProbability(ctx, 2000)
+	if exact := scoring.BonusProbability(g.Scorecards[0]); p+4*stderr >= exact {
+		t.Errorf("SampleBonusProbability() = %v ± %v under random play; want less than %v", p, stderr, exact)
+	}
+}
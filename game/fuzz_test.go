@@ -0,0 +1,103 @@
+package game
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+// playChoices plays g to the end, picking the move at choices[i] (modulo
+// the number of moves) for the i-th decision and at random after that,
+// and checks the engine's invariants after every move.
+func playChoices(t *testing.T, g *Game, choices []byte) {
+	t.Helper()
+	selectionsLeft := make([]int, len(g.Scorecards))
+	for i, ps := range g.Scorecards {
+		selectionsLeft[i] = ps.GetTurnsLeft()
+	}
+	const maxPlies = scoring.Categories * scoring.MaxReRolls * 8
+	for ply := 0; !g.IsOver(); ply++ {
+		if ply > maxPlies*len(g.Scorecards) {
+			t.Fatalf("game not over after %d moves: %s", ply, g.Notation())
+		}
+		if g.CurTurn.RollCnt == 0 {
+			g.CurTurn.CurrentRoll = g.RandRoll()
+			g.CurTurn.RollCnt = 1
+		}
+		moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+		if len(moves) == 0 {
+			t.Fatalf("no moves in %s", g.Notation())
+		}
+		choice := g.Rng.IntN(len(moves))
+		if ply < len(choices) {
+			choice = int(choices[ply]) % len(moves)
+		}
+		m := moves[choice]
+		if err := g.ValidateMove(m); err != nil {
+			t.Fatalf("generated move %s is invalid in %s: %v", m, g.Notation(), err)
+		}
+
+		pIdx := g.CurPlayerIdx
+		before := g.Scorecards[pIdx]
+		gameOver := g.DoMove(m)
+		after := g.Scorecards[pIdx]
+
+		if after.CatMask&before.CatMask != before.CatMask {
+			t.Fatalf("%s cleared categories: %#x -> %#x", m, before.CatMask, after.CatMask)
+		}
+		if after.Score() < before.Score() {
+			t.Fatalf("%s lowered the score: %d -> %d", m, before.Score(), after.Score())
+		}
+		for _, ps := range g.Scorecards {
+			if ps.Score() > ps.MaxTheoreticalScore() {
+				t.Fatalf("score %d is over the maximum %d:\n%s", ps.Score(), ps.MaxTheoreticalScore(), ps.Pretty())
+			}
+		}
+		if g.CurTurn.RollCnt > scoring.MaxReRolls {
+			t.Fatalf("rolled %d times in a turn", g.CurTurn.RollCnt)
+		}
+		if !m.Reroll {
+			selectionsLeft[pIdx]--
+		}
+		if gameOver != g.IsOver() {
+			t.Fatalf("DoMove(%s) = %t; game over is %t", m, gameOver, g.IsOver())
+		}
+	}
+	for i, n := range selectionsLeft {
+		if n != 0 {
+			t.Errorf("player %d finished with %d selections left", i+1, n)
+		}
+	}
+}
+
+func FuzzGameInvariants(f *testing.F) {
+	f.Add(uint64(1), uint64(2), uint8(1), []byte{})
+	f.Add(uint64(3), uint64(4), uint8(2), []byte{0, 40, 40, 0, 7})
+	f.Add(uint64(5), uint64(6), uint8(3), []byte{255, 255, 255, 255})
+	f.Fuzz(func(t *testing.T, seed1, seed2 uint64, players uint8, choices []byte) {
+		g := New(rand.NewPCG(seed1, seed2), make([]Player, 1+players%4))
+		playChoices(t, g, choices)
+	})
+}
+
+func FuzzPositionPlayout(f *testing.F) {
+	f.Add("-/- - 0 1", []byte{})
+	f.Add("ss:30/- 55521 1 2", []byte{3})
+	f.Add("1s:3,6s:0,fh:25,yz:150 66666 3 1", []byte{1, 2})
+	f.Add("1s:3/- 66621 1 1", []byte{})
+	f.Fuzz(func(t *testing.T, position string, choices []byte) {
+		g, err := ParsePosition(position)
+		if err != nil {
+			return
+		}
+		if got := g.Notation(); got != position {
+			if _, err := ParsePosition(got); err != nil {
+				t.Fatalf("notation %q of parsed position %q does not parse: %v", got, position, err)
+			}
+		}
+		g.Src = rand.NewPCG(1, 2)
+		g.Rng = rand.New(g.Src)
+		playChoices(t, g, choices)
+	})
+}
@@ -0,0 +1,242 @@
+// Package game is the yatzy engine: it holds the state of a game between
+// players, generates and validates their moves and plays games out.
+package game
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/bits"
+	"math/rand/v2"
+	"time"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+func (g *Game) randDie() dice.Die {
+	return dice.Die(1 + g.Rng.IntN(6))
+}
+
+// RandRoll rolls all five dice.
+func (g *Game) RandRoll() dice.Roll {
+	return dice.RollAt(g.Rng.IntN(dice.OrderedRolls))
+}
+
+// RandRollWithKept rerolls the dice not in hold, returning the
+// canonical roll.
+func (g *Game) RandRollWithKept(hold dice.Counts) dice.Roll {
+	r := hold
+	for range 5 - hold.Len() {
+		r = r.Add(g.randDie())
+	}
+	return r.Roll()
+}
+
+// Turn is the dice of the current player's turn.
+type Turn struct {
+	CurrentRoll dice.Roll
+	// the number of rolls used in a turn (up to 3).
+	// a turn that has just started will have a RollCnt
+	// of zero.
+	RollCnt int
+}
+
+func (t *Turn) reset() {
+	*t = Turn{}
+}
+
+// Move is a decision for the current roll. Moves are small values so
+// they can be generated without allocating (see GetMovesForCurrentPlayer).
+type Move struct {
+	Hold   dice.Counts      // dice to keep (if re-rolling)
+	Cat    scoring.Category // category to fill (if not re-rolling)
+	Score  uint16           // score Cat is filled with, including any bonus
+	Reroll bool             // whether to re-roll
+}
+
+func (m Move) String() string {
+	if m.Reroll {
+		return fmt.Sprintf("reroll holding %s", m.Hold)
+	}
+	return fmt.Sprintf("select %s for %d", m.Cat, m.Score)
+}
+
+// MaxMoves is the most moves there can be for a roll: every category and
+// every distinct hold of up to four dice.
+const MaxMoves = scoring.Categories + 0b11111
+
+// Player picks one of moves for the current player of g, returning its
+// index. It should return once ctx is done.
+type Player interface {
+	PickMove(ctx context.Context, g *Game, moves []Move) int
+}
+
+// Game is a game of yatzy between players, one scorecard each.
+type Game struct {
+	Scorecards   []scoring.Scorecard
+	CurTurn      *Turn
+	Players      []Player
+	CurPlayerIdx int
+	Rng          *rand.Rand
+	Src          *rand.PCG // source of Rng, kept so its state can be saved.
+
+	// history holds the state before each action applied with Play or
+	// EnterRoll and future the actions reverted by Undo (see history.go).
+	history []State
+	future  []undone
+
+	// moveBuf is reused for generating moves during simulations.
+	moveBuf []Move
+}
+
+// New starts a game between players, rolling dice with src.
+func New(src *rand.PCG, players []Player) *Game {
+	return &Game{
+		Scorecards: make([]scoring.Scorecard, len(players)),
+		CurTurn:    new(Turn),
+		Players:    players,
+		Rng:        rand.New(src),
+		Src:        src,
+		moveBuf:    make([]Move, 0, MaxMoves),
+	}
+}
+
+// Clone returns a copy of g without its history that shares its players
+// and rng, for playing out.
+func (g *Game) Clone() *Game {
+	scorecards := make([]scoring.Scorecard, len(g.Scorecards))
+	copy(scorecards, g.Scorecards)
+	turn := &Turn{
+		CurrentRoll: g.CurTurn.CurrentRoll,
+		RollCnt:     g.CurTurn.RollCnt,
+	}
+	return &Game{
+		Scorecards:   scorecards,
+		CurTurn:      turn,
+		Players:      g.Players, // not cloned (they don't have state)
+		CurPlayerIdx: g.CurPlayerIdx,
+		Rng:          g.Rng,
+		Src:          g.Src,
+		moveBuf:      make([]Move, 0, MaxMoves),
+	}
+}
+
+// GetMovesForCurrentPlayer appends every move available to the current
+// player for roll r to buf. It does not allocate if buf has room for
+// MaxMoves.
+func (g *Game) GetMovesForCurrentPlayer(r dice.Roll, buf []Move) []Move {
+	pIdx := g.CurPlayerIdx
+	ps := g.Scorecards[pIdx]
+
+	cur := (^ps.CatMask & 0x1FFF)
+	for cur > 0 {
+		c := scoring.Category(bits.TrailingZeros16(cur))
+		cur ^= (1 << c)
+		next := ps.Update(r, c)
+		buf = append(buf, Move{Cat: c, Score: next.ScoresByCategory[c]})
+	}
+
+	canRollAgain := g.CurTurn.RollCnt < scoring.MaxReRolls
+	if canRollAgain {
+		rerolls := len(buf)
+		// Every subset of dice except all of them (can't keep all and re-roll).
+		for mask := 1; mask < 0b11111; mask++ {
+			var hold dice.Counts
+			for idx := range 5 {
+				if mask&(1<<idx) != 0 {
+					hold = hold.Add(r.Die(idx))
+				}
+			}
+			seen := false
+			for _, m := range buf[rerolls:] {
+				if m.Hold == hold {
+					seen = true
+					break
+				}
+			}
+			if !seen {
+				buf = append(buf, Move{Hold: hold, Reroll: true})
+			}
+		}
+	}
+	return buf
+}
+
+func (g *Game) DoMove(m Move) bool {
+	if m.Reroll {
+		g.CurTurn.CurrentRoll = g.RandRollWithKept(m.Hold)
+		g.CurTurn.RollCnt += 1
+		return false
+	}
+	pIdx := g.CurPlayerIdx
+	ps := g.Scorecards[pIdx]
+	turnsLeft := ps.GetTurnsLeft()
+	gameOver := pIdx == len(g.Scorecards)-1 && turnsLeft <= 1
+	g.Scorecards[pIdx] = ps.Update(g.CurTurn.CurrentRoll, m.Cat)
+	g.CurPlayerIdx = (pIdx + 1) % len(g.Scorecards)
+	g.CurTurn.reset()
+	return gameOver
+}
+
+func (g *Game) RunSimulation(ctx context.Context) {
+	var gameOver bool
+	// Start round.
+	for !gameOver {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		// Start player turn.
+		g.CurTurn.CurrentRoll = g.RandRoll()
+		g.CurTurn.RollCnt = 1
+		curPlayer := g.CurPlayerIdx
+		// Selecting a category resets the turn, even when the same player
+		// goes again in a single player game.
+		for g.CurTurn.RollCnt > 0 && !gameOver {
+			moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, g.moveBuf[:0])
+			moveIdx := g.Players[curPlayer].PickMove(ctx, g, moves)
+			gameOver = g.DoMove(moves[moveIdx])
+		}
+	}
+}
+
+// DoPly runs a single ply for the current player. Returns whether
+// the game is over. Moves picked by players are validated before they
+// are applied.
+func (g *Game) DoPly() (bool, error) {
+	// Start player turn, unless resuming one that is already underway.
+	if g.CurTurn.RollCnt == 0 {
+		if err := g.EnterRoll(0, g.RandRoll()); err != nil {
+			return false, err
+		}
+	}
+	curPlayer := g.CurPlayerIdx
+	for g.CurTurn.RollCnt > 0 {
+		log.Printf("player [%s]: rolled %s", g.Players[curPlayer], g.CurTurn.CurrentRoll)
+		moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		moveIdx := g.Players[curPlayer].PickMove(ctx, g, moves)
+		cancel()
+		if moveIdx < 0 || moveIdx >= len(moves) {
+			return false, fmt.Errorf("player [%s]: %w: index %d of %d", g.Players[curPlayer], ErrUnknownMove, moveIdx, len(moves))
+		}
+		move := moves[moveIdx]
+		log.Printf("player [%s]: %s", g.Players[curPlayer], move)
+		gameOver, err := g.Play(move)
+		if err != nil {
+			return false, fmt.Errorf("player [%s]: %w", g.Players[curPlayer], err)
+		}
+		if !move.Reroll {
+			ps := g.Scorecards[curPlayer]
+			log.Print(ps.Pretty())
+			log.Printf("player [%s]: has %d points", g.Players[curPlayer], ps.Score())
+		}
+		if gameOver {
+			return true, nil
+		}
+	}
+	return false, nil
+}
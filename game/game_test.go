@@ -0,0 +1,124 @@
+package game
+
+import (
+	"context"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/scoring"
+	"github.com/google/go-cmp/cmp"
+)
+
+// randomPlayer picks moves uniformly at random, like strategy.RandomPlayer,
+// which these tests cannot import without an import cycle.
+type randomPlayer struct {
+	rng *rand.Rand
+}
+
+func (rp *randomPlayer) PickMove(_ context.Context, _ *Game, moves []Move) int {
+	return rp.rng.IntN(len(moves))
+}
+
+func (rp *randomPlayer) MoveProbabilities(_ *Game, moves []Move, probs []float64) {
+	for i := range moves {
+		probs[i] = 1 / float64(len(moves))
+	}
+}
+
+func TestGameGetMovesForCurrentPlayer(t *testing.T) {
+	g := &Game{
+		CurTurn: new(Turn),
+		Scorecards: []scoring.Scorecard{
+			scoring.Scorecard{},
+		},
+	}
+
+	moves := g.GetMovesForCurrentPlayer(dice.RollOf([5]dice.Die{dice.DIE_SIX, dice.DIE_FIVE, dice.DIE_FOUR, dice.DIE_THREE, dice.DIE_ONE}), nil)
+	var movesStr []string
+	for _, m := range moves {
+		movesStr = append(movesStr, m.String())
+	}
+
+	want := []string{
+		"select ones for 1",
+		"select twos for 0",
+		"select threes for 3",
+		"select fours for 4",
+		"select fives for 5",
+		"select sixes for 6",
+		"select three of a kind for 0",
+		"select four of a kind for 0",
+		"select full house for 0",
+		"select small straight for 30",
+		"select large straight for 0",
+		"select chance for 19",
+		"select yatzy for 0",
+		"reroll holding six",
+		"reroll holding five",
+		"reroll holding six,five",
+		"reroll holding four",
+		"reroll holding six,four",
+		"reroll holding five,four",
+		"reroll holding six,five,four",
+		"reroll holding three",
+		"reroll holding six,three",
+		"reroll holding five,three",
+		"reroll holding six,five,three",
+		"reroll holding four,three",
+		"reroll holding six,four,three",
+		"reroll holding five,four,three",
+		"reroll holding six,five,four,three",
+		"reroll holding one",
+		"reroll holding six,one",
+		"reroll holding five,one",
+		"reroll holding six,five,one",
+		"reroll holding four,one",
+		"reroll holding six,four,one",
+		"reroll holding five,four,one",
+		"reroll holding six,five,four,one",
+		"reroll holding three,one",
+		"reroll holding six,three,one",
+		"reroll holding five,three,one",
+		"reroll holding six,five,three,one",
+		"reroll holding four,three,one",
+		"reroll holding six,four,three,one",
+		"reroll holding five,four,three,one",
+	}
+	if diff := cmp.Diff(movesStr, want); diff != "" {
+		t.Errorf("moves do not match (-got, +want):\n%s", diff)
+	}
+}
+
+func TestRunSimulationAllocs(t *testing.T) {
+	g := New(rand.NewPCG(1, 2), make([]Player, 2))
+	for i := range g.Players {
+		g.Players[i] = &randomPlayer{g.Rng}
+	}
+	ctx := context.Background()
+	allocs := testing.AllocsPerRun(100, func() {
+		clear(g.Scorecards)
+		g.CurTurn.reset()
+		g.CurPlayerIdx = 0
+		g.RunSimulation(ctx)
+	})
+	if allocs != 0 {
+		t.Errorf("RunSimulation() allocated %v times per playout; want 0", allocs)
+	}
+}
+
+// BenchmarkPlayout measures the playouts that strategy.MonteCarloPlayer runs for
+// every candidate move: a random game from the current position to the end.
+func BenchmarkPlayout(b *testing.B) {
+	g := New(rand.NewPCG(1, 2), make([]Player, 2))
+	for i := range g.Players {
+		g.Players[i] = &randomPlayer{g.Rng}
+	}
+	ctx := context.Background()
+	b.ResetTimer()
+	for range b.N {
+		sg := g.Clone()
+		sg.RunSimulation(ctx)
+	}
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "playouts/s")
+}
@@ -0,0 +1,124 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+// State is everything undo needs to put a game back how it was.
+type State struct {
+	Scorecards   []scoring.Scorecard
+	CurTurn      Turn
+	CurPlayerIdx int
+	// Rolled is set if the action applied to this state was entering a roll,
+	// and held is the dice kept for it, so that undoing a reroll entered by
+	// hand can go back to entering its dice.
+	Rolled bool
+	Held   dice.Counts
+}
+
+func (g *Game) state() State {
+	return State{
+		Scorecards:   slices.Clone(g.Scorecards),
+		CurTurn:      *g.CurTurn,
+		CurPlayerIdx: g.CurPlayerIdx,
+	}
+}
+
+func (g *Game) restore(s State) {
+	g.Scorecards = slices.Clone(s.Scorecards)
+	*g.CurTurn = s.CurTurn
+	g.CurPlayerIdx = s.CurPlayerIdx
+}
+
+// undone is an action reverted by Undo: the state recorded before it and
+// the state it led to.
+type undone struct {
+	before, after State
+}
+
+// MaxHistory is how many actions can be undone. Older states are
+// forgotten, so games that are played for a long time, such as on a
+// server, do not grow without limit.
+const MaxHistory = 256
+
+// record saves the current state so the action about to be applied can be
+// undone. Any undone actions can no longer be redone.
+func (g *Game) record(rolled bool, held dice.Counts) {
+	s := g.state()
+	s.Rolled = rolled
+	s.Held = held
+	if len(g.history) >= MaxHistory {
+		g.history = slices.Delete(g.history, 0, len(g.history)-MaxHistory+1)
+	}
+	g.history = append(g.history, s)
+	g.future = g.future[:0]
+}
+
+// Undo reverts the last roll, reroll or selection applied with EnterRoll
+// or play, returning the state that was restored.
+func (g *Game) Undo() (State, error) {
+	if len(g.history) == 0 {
+		return State{}, ErrNothingToUndo
+	}
+	s := g.history[len(g.history)-1]
+	g.future = append(g.future, undone{before: s, after: g.state()})
+	g.restore(s)
+	g.history = g.history[:len(g.history)-1]
+	return s, nil
+}
+
+// Redo reapplies the last action reverted by undo.
+func (g *Game) Redo() error {
+	if len(g.future) == 0 {
+		return ErrNothingToRedo
+	}
+	// The recorded state is kept rather than taken again, so undoing a
+	// reroll entered by hand still goes back to entering its dice.
+	u := g.future[len(g.future)-1]
+	g.history = append(g.history, u.before)
+	g.restore(u.after)
+	g.future = g.future[:len(g.future)-1]
+	return nil
+}
+
+// EnterRoll sets the dice rolled by the current player, for dice that are
+// rolled outside of the engine (or by it, see DoPly). hold is the dice kept
+// from the previous roll and must be empty for the first roll of a turn.
+func (g *Game) EnterRoll(hold dice.Counts, r dice.Roll) error {
+	if g.IsOver() {
+		return ErrGameOver
+	}
+	if g.CurTurn.RollCnt > 0 {
+		if g.CurTurn.RollCnt >= scoring.MaxReRolls {
+			return fmt.Errorf("%w: already rolled %d times", ErrNoRerollsLeft, g.CurTurn.RollCnt)
+		}
+		if err := dice.ValidateHold(g.CurTurn.CurrentRoll, hold); err != nil {
+			return err
+		}
+	} else if hold != 0 {
+		return fmt.Errorf("%w: nothing to hold before the first roll", ErrInvalidHold)
+	}
+	for _, d := range r.Dice() {
+		if d < dice.DIE_ONE || d > dice.DIE_SIX {
+			return fmt.Errorf("%w: die value %d", ErrInvalidHold, d)
+		}
+	}
+	if !r.Counts().Contains(hold) {
+		return fmt.Errorf("rolled %s while holding %s: %w", r, hold, ErrHoldNotInRoll)
+	}
+
+	g.record(true, hold)
+	g.CurTurn.CurrentRoll = r
+	g.CurTurn.RollCnt++
+	return nil
+}
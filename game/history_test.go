@@ -0,0 +1,207 @@
+package game
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/scoring"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGameUndoRedo(t *testing.T) {
+	opts := []cmp.Option{
+		cmp.AllowUnexported(State{}, Turn{}, scoring.Scorecard{}),
+	}
+	g := New(rand.NewPCG(3, 4), make([]Player, 2))
+	if _, err := g.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Undo() = %v; want %v", err, ErrNothingToUndo)
+	}
+
+	// Play a few turns, remembering the state before every action.
+	var states []State
+	for len(states) < 20 {
+		states = append(states, g.state())
+		if g.CurTurn.RollCnt == 0 {
+			if err := g.EnterRoll(0, g.RandRoll()); err != nil {
+				t.Fatalf("EnterRoll() returned unexpected error: %v", err)
+			}
+			continue
+		}
+		moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+		if _, err := g.Play(moves[g.Rng.IntN(len(moves))]); err != nil {
+			t.Fatalf("Play() returned unexpected error: %v", err)
+		}
+	}
+	final := g.state()
+
+	for i := len(states) - 1; i >= 0; i-- {
+		if _, err := g.Undo(); err != nil {
+			t.Fatalf("Undo() returned unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(g.state(), states[i], opts...); diff != "" {
+			t.Fatalf("state after undo %d does not match (-got, +want):\n%s", len(states)-i, diff)
+		}
+	}
+	if _, err := g.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Undo() = %v; want %v", err, ErrNothingToUndo)
+	}
+
+	for i := 1; i < len(states); i++ {
+		if err := g.Redo(); err != nil {
+			t.Fatalf("Redo() returned unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(g.state(), states[i], opts...); diff != "" {
+			t.Fatalf("state after redo %d does not match (-got, +want):\n%s", i, diff)
+		}
+	}
+	if err := g.Redo(); err != nil {
+		t.Fatalf("Redo() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(g.state(), final, opts...); diff != "" {
+		t.Fatalf("state after redoing everything does not match (-got, +want):\n%s", diff)
+	}
+	if err := g.Redo(); !errors.Is(err, ErrNothingToRedo) {
+		t.Errorf("Redo() = %v; want %v", err, ErrNothingToRedo)
+	}
+
+	// A new action after undo discards what could have been redone.
+	if _, err := g.Undo(); err != nil {
+		t.Fatalf("Undo() returned unexpected error: %v", err)
+	}
+	if g.CurTurn.RollCnt == 0 {
+		err := g.EnterRoll(0, g.RandRoll())
+		if err != nil {
+			t.Fatalf("EnterRoll() returned unexpected error: %v", err)
+		}
+	} else if _, err := g.Play(g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)[0]); err != nil {
+		t.Fatalf("Play() returned unexpected error: %v", err)
+	}
+	if err := g.Redo(); !errors.Is(err, ErrNothingToRedo) {
+		t.Errorf("Redo() = %v; want %v", err, ErrNothingToRedo)
+	}
+}
+
+func TestGameRedoEnteredReroll(t *testing.T) {
+	g := New(rand.NewPCG(3, 4), make([]Player, 1))
+	if err := g.EnterRoll(0, dice.NewRoll(5, 5, 2, 1, 3)); err != nil {
+		t.Fatal(err)
+	}
+	held := dice.Count(dice.DIE_FIVE, dice.DIE_FIVE)
+	if err := g.EnterRoll(held, dice.NewRoll(5, 5, 5, 6, 6)); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		s, err := g.Undo()
+		if err != nil {
+			t.Fatalf("Undo() returned unexpected error: %v", err)
+		}
+		if !s.Rolled || s.Held != held {
+			t.Errorf("undoing the reroll restored rolled %t holding %s; want rolled holding %s", s.Rolled, s.Held, held)
+		}
+		if err := g.Redo(); err != nil {
+			t.Fatalf("Redo() returned unexpected error: %v", err)
+		}
+	}
+}
+
+func TestGameHistoryLimit(t *testing.T) {
+	g := New(rand.NewPCG(5, 6), make([]Player, 1))
+	for range MaxHistory + 10 {
+		if g.CurTurn.RollCnt == 0 {
+			if err := g.EnterRoll(0, g.RandRoll()); err != nil {
+				t.Fatalf("EnterRoll() returned unexpected error: %v", err)
+			}
+			continue
+		}
+		moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+		if _, err := g.Play(moves[0]); err != nil {
+			t.Fatalf("Play() returned unexpected error: %v", err)
+		}
+		if g.IsOver() {
+			g.Scorecards[0] = scoring.Scorecard{}
+		}
+	}
+	undone := 0
+	for ; undone <= MaxHistory; undone++ {
+		if _, err := g.Undo(); err != nil {
+			break
+		}
+	}
+	if undone != MaxHistory {
+		t.Errorf("undid %d actions; want the last %d", undone, MaxHistory)
+	}
+}
+
+func TestGameEnterRoll(t *testing.T) {
+	roll := dice.RollOf([5]dice.Die{dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_TWO, dice.DIE_ONE})
+	for _, tt := range []struct {
+		name    string
+		rollCnt int
+		hold    dice.Counts
+		r       dice.Roll
+		want    error
+	}{
+		{
+			name:    "first roll",
+			rollCnt: 0,
+			r:       roll,
+		},
+		{
+			name:    "first roll holding",
+			rollCnt: 0,
+			hold:    dice.Count(dice.DIE_FIVE),
+			r:       roll,
+			want:    ErrInvalidHold,
+		},
+		{
+			name:    "reroll",
+			rollCnt: 1,
+			hold:    dice.Count(dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_FIVE),
+			r:       dice.RollOf([5]dice.Die{dice.DIE_FIVE, dice.DIE_SIX, dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_SIX}),
+		},
+		{
+			name:    "reroll without held dice",
+			rollCnt: 1,
+			hold:    dice.Count(dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_FIVE),
+			r:       dice.RollOf([5]dice.Die{dice.DIE_FIVE, dice.DIE_SIX, dice.DIE_SIX, dice.DIE_FIVE, dice.DIE_SIX}),
+			want:    ErrHoldNotInRoll,
+		},
+		{
+			name:    "reroll holding dice not rolled",
+			rollCnt: 1,
+			hold:    dice.Count(dice.DIE_SIX),
+			r:       dice.RollOf([5]dice.Die{dice.DIE_FIVE, dice.DIE_SIX, dice.DIE_SIX, dice.DIE_FIVE, dice.DIE_SIX}),
+			want:    ErrHoldNotInRoll,
+		},
+		{
+			name:    "reroll after last roll",
+			rollCnt: scoring.MaxReRolls,
+			r:       roll,
+			want:    ErrNoRerollsLeft,
+		},
+		{
+			name:    "unset dice",
+			rollCnt: 0,
+			want:    ErrInvalidHold,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Game{
+				Scorecards: []scoring.Scorecard{{}},
+				CurTurn:    &Turn{CurrentRoll: roll, RollCnt: tt.rollCnt},
+			}
+			err := g.EnterRoll(tt.hold, tt.r)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("EnterRoll() = %v; want %v", err, tt.want)
+			}
+			if err != nil {
+				return
+			}
+			if g.CurTurn.CurrentRoll != tt.r || g.CurTurn.RollCnt != tt.rollCnt+1 {
+				t.Errorf("turn after EnterRoll() = %s (roll %d); want %s (roll %d)", g.CurTurn.CurrentRoll, g.CurTurn.RollCnt, tt.r, tt.rollCnt+1)
+			}
+		})
+	}
+}
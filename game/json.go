@@ -0,0 +1,153 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+// The JSON schema shared by saved games, API payloads and test fixtures:
+//
+//	roll:      [5, 5, 5, 2, 1] (dice in roll order, null if not rolled)
+//	scorecard: {"scores": {"small straight": 30, "yatzy": 0}}
+//	turn:      {"roll": <roll>, "rollCount": 1}
+//	move:      {"action": "reroll", "hold": [5, 5, 5]}
+//	           {"action": "select", "category": "chance", "score": 18}
+//	game:      {"scorecards": [<scorecard>, ...], "turn": <turn>, "currentPlayer": 0}
+//
+// Only filled categories appear in a scorecard's scores. Every type is
+// validated when it is decoded.
+
+// ErrInvalidJSON is returned when decoding JSON that is well formed but
+// does not describe a valid value, by every type in this module.
+var ErrInvalidJSON = dice.ErrInvalidJSON
+
+type turnJSON struct {
+	Roll      dice.Roll `json:"roll"`
+	RollCount int       `json:"rollCount"`
+}
+
+func (t Turn) MarshalJSON() ([]byte, error) {
+	tj := turnJSON{RollCount: t.RollCnt}
+	if t.RollCnt > 0 {
+		tj.Roll = t.CurrentRoll
+	}
+	return json.Marshal(tj)
+}
+
+func (t *Turn) UnmarshalJSON(data []byte) error {
+	var tj turnJSON
+	if err := json.Unmarshal(data, &tj); err != nil {
+		return err
+	}
+	if tj.RollCount < 0 || tj.RollCount > scoring.MaxReRolls {
+		return fmt.Errorf("%w: roll count %d must be between 0 and %d", ErrInvalidJSON, tj.RollCount, scoring.MaxReRolls)
+	}
+	if (tj.RollCount == 0) != (tj.Roll == 0) {
+		return fmt.Errorf("%w: roll must be set if and only if the roll count is positive", ErrInvalidJSON)
+	}
+	t.CurrentRoll = tj.Roll
+	t.RollCnt = tj.RollCount
+	return nil
+}
+
+const (
+	actionReroll = "reroll"
+	actionSelect = "select"
+)
+
+type moveJSON struct {
+	Action   string            `json:"action"`
+	Hold     []int             `json:"hold,omitempty"`
+	Category *scoring.Category `json:"category,omitempty"`
+	Score    *uint16           `json:"score,omitempty"`
+}
+
+func (m Move) MarshalJSON() ([]byte, error) {
+	if m.Reroll {
+		return json.Marshal(moveJSON{
+			Action: actionReroll,
+			Hold:   dice.Values(m.Hold.Dice()),
+		})
+	}
+	return json.Marshal(moveJSON{
+		Action:   actionSelect,
+		Category: &m.Cat,
+		Score:    &m.Score,
+	})
+}
+
+func (m *Move) UnmarshalJSON(data []byte) error {
+	var mj moveJSON
+	if err := json.Unmarshal(data, &mj); err != nil {
+		return err
+	}
+	switch mj.Action {
+	case actionReroll:
+		hold, err := dice.ParseValues(mj.Hold)
+		if err != nil {
+			return err
+		}
+		if len(hold) >= 5 {
+			return fmt.Errorf("%w: reroll must leave at least one die to reroll", ErrInvalidJSON)
+		}
+		*m = NewRerollMove(hold...)
+		return nil
+	case actionSelect:
+		if mj.Category == nil || mj.Score == nil {
+			return fmt.Errorf("%w: select needs category and score", ErrInvalidJSON)
+		}
+		if !scoring.ValidCategoryScore(*mj.Category, *mj.Score) {
+			return fmt.Errorf("%w: %d is not a possible %s score", ErrInvalidJSON, *mj.Score, *mj.Category)
+		}
+		*m = Move{Cat: *mj.Category, Score: *mj.Score}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown move action %q", ErrInvalidJSON, mj.Action)
+	}
+}
+
+type gameJSON struct {
+	Scorecards    []scoring.Scorecard `json:"scorecards"`
+	Turn          Turn                `json:"turn"`
+	CurrentPlayer int                 `json:"currentPlayer"`
+}
+
+// MarshalJSON encodes the position of the game. Players and the rng are
+// not included.
+func (g *Game) MarshalJSON() ([]byte, error) {
+	return json.Marshal(gameJSON{
+		Scorecards:    g.Scorecards,
+		Turn:          *g.CurTurn,
+		CurrentPlayer: g.CurPlayerIdx,
+	})
+}
+
+// UnmarshalJSON decodes the position of the game. Players and the rng are
+// left untouched.
+func (g *Game) UnmarshalJSON(data []byte) error {
+	var gj gameJSON
+	if err := json.Unmarshal(data, &gj); err != nil {
+		return err
+	}
+	if len(gj.Scorecards) == 0 {
+		return fmt.Errorf("%w: game has no scorecards", ErrInvalidJSON)
+	}
+	if gj.CurrentPlayer < 0 || gj.CurrentPlayer >= len(gj.Scorecards) {
+		return fmt.Errorf("%w: current player %d must be between 0 and %d", ErrInvalidJSON, gj.CurrentPlayer, len(gj.Scorecards)-1)
+	}
+	next := &Game{
+		Scorecards:   gj.Scorecards,
+		CurTurn:      &gj.Turn,
+		CurPlayerIdx: gj.CurrentPlayer,
+	}
+	if err := next.validateTurnOrder(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+	}
+	g.Scorecards = next.Scorecards
+	g.CurTurn = next.CurTurn
+	g.CurPlayerIdx = next.CurPlayerIdx
+	return nil
+}
@@ -0,0 +1,139 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/scoring"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGameJSON(t *testing.T) {
+	opts := []cmp.Option{
+		cmp.AllowUnexported(Game{}, Turn{}, scoring.Scorecard{}),
+	}
+	g := &Game{
+		Scorecards: []scoring.Scorecard{
+			{
+				ScoresByCategory: [13]uint16{
+					scoring.CAT_SIXES:          18,
+					scoring.CAT_SMALL_STRAIGHT: 30,
+					scoring.CAT_YATZY:          0,
+				},
+				CatMask: 1<<scoring.CAT_SIXES | 1<<scoring.CAT_SMALL_STRAIGHT | 1<<scoring.CAT_YATZY,
+			},
+			{
+				ScoresByCategory: [13]uint16{
+					scoring.CAT_ONES:   3,
+					scoring.CAT_CHANCE: 20,
+				},
+				CatMask: 1<<scoring.CAT_ONES | 1<<scoring.CAT_CHANCE,
+			},
+		},
+		CurTurn: &Turn{
+			CurrentRoll: dice.RollOf([5]dice.Die{dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_TWO, dice.DIE_ONE}),
+			RollCnt:     2,
+		},
+		CurPlayerIdx: 1,
+	}
+
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned unexpected error: %v", err)
+	}
+	want := `{"scorecards":[{"scores":{"sixes":18,"small straight":30,"yatzy":0}},{"scores":{"chance":20,"ones":3}}],"turn":{"roll":[5,5,5,2,1],"rollCount":2},"currentPlayer":1}`
+	if diff := cmp.Diff(string(data), want); diff != "" {
+		t.Errorf("json does not match (-got, +want):\n%s", diff)
+	}
+
+	got := new(Game)
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(got, g, opts...); diff != "" {
+		t.Errorf("game does not round trip (-got, +want):\n%s", diff)
+	}
+}
+
+func TestMoveJSON(t *testing.T) {
+	opts := []cmp.Option{
+		cmp.AllowUnexported(Move{}),
+	}
+	g := &Game{
+		Scorecards: []scoring.Scorecard{{}},
+		CurTurn: &Turn{
+			CurrentRoll: dice.RollOf([5]dice.Die{dice.DIE_SIX, dice.DIE_FIVE, dice.DIE_FOUR, dice.DIE_THREE, dice.DIE_ONE}),
+			RollCnt:     1,
+		},
+	}
+	for _, tt := range []struct {
+		m    Move
+		want string
+	}{
+		{
+			m:    NewRerollMove(dice.DIE_SIX, dice.DIE_FIVE),
+			want: `{"action":"reroll","hold":[6,5]}`,
+		},
+		{
+			m:    NewRerollMove(dice.DIE_ONE),
+			want: `{"action":"reroll","hold":[1]}`,
+		},
+		{
+			m:    g.SelectMove(scoring.CAT_SMALL_STRAIGHT),
+			want: `{"action":"select","category":"small straight","score":30}`,
+		},
+	} {
+		data, err := json.Marshal(tt.m)
+		if err != nil {
+			t.Fatalf("json.Marshal(%s) returned unexpected error: %v", tt.m, err)
+		}
+		if diff := cmp.Diff(string(data), tt.want); diff != "" {
+			t.Errorf("json for %s does not match (-got, +want):\n%s", tt.m, diff)
+		}
+
+		var got Move
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("json.Unmarshal(%s) returned unexpected error: %v", data, err)
+		}
+		if diff := cmp.Diff(got, tt.m, opts...); diff != "" {
+			t.Errorf("move does not round trip (-got, +want):\n%s", diff)
+		}
+		if err := g.ValidateMove(got); err != nil {
+			t.Errorf("decoded move %s is invalid: %v", got, err)
+		}
+	}
+}
+
+func TestJSONDecodeErrors(t *testing.T) {
+	for _, tt := range []struct {
+		data string
+		v    any
+	}{
+		{`[1,2,3,4]`, new(dice.Roll)},
+		{`[1,2,3,4,7]`, new(dice.Roll)},
+		{`[0,2,3,4,5]`, new(dice.Roll)},
+		{`{"scores":{"sevens":7}}`, new(scoring.Scorecard)},
+		{`{"scores":{"fives":7}}`, new(scoring.Scorecard)},
+		{`{"scores":{"full house":20}}`, new(scoring.Scorecard)},
+		{`{"scores":{"chance":0}}`, new(scoring.Scorecard)},
+		{`{"scores":{"yatzy":100}}`, new(scoring.Scorecard)},
+		{`{"roll":[1,2,3,4,5],"rollCount":4}`, new(Turn)},
+		{`{"roll":[1,2,3,4,5],"rollCount":0}`, new(Turn)},
+		{`{"roll":null,"rollCount":1}`, new(Turn)},
+		{`{"action":"pass"}`, new(Move)},
+		{`{"action":"reroll","hold":[1,2,3,4,5]}`, new(Move)},
+		{`{"action":"reroll","hold":[9]}`, new(Move)},
+		{`{"action":"select","category":"chance"}`, new(Move)},
+		{`{"action":"select","score":18}`, new(Move)},
+		{`{"action":"select","category":"chance","score":31}`, new(Move)},
+		{`{"action":"select","category":"sevens","score":7}`, new(Move)},
+		{`{"scorecards":[],"turn":{"roll":null,"rollCount":0},"currentPlayer":0}`, new(Game)},
+		{`{"scorecards":[{"scores":{}}],"turn":{"roll":null,"rollCount":0},"currentPlayer":1}`, new(Game)},
+	} {
+		if err := json.Unmarshal([]byte(tt.data), tt.v); !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("json.Unmarshal(%s) = %v; want %v", tt.data, err, ErrInvalidJSON)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+// Position notation is a compact, single line encoding of a game position
+// that is easy to paste into chat. It has four space separated fields:
+//
+//	<scorecards> <dice> <rolls> <player>
+//
+// scorecards is one scorecard per player separated by "/". A scorecard lists
+// its filled categories as code:score pairs separated by "," (see scoring.Category.Code),
+// or "-" when nothing has been filled yet. dice is the current roll as five
+// digits in roll order, or "-" when the turn has not been rolled yet. rolls is
+// the number of rolls used this turn and player is the 1-based index of the
+// player to move.
+//
+// For example, the second of two players having rolled once for 5,5,5,2,1
+// after the first player took a small straight:
+//
+//	ss:30/- 55521 1 2
+
+// ErrInvalidPosition is returned when parsing malformed position notation.
+var ErrInvalidPosition = errors.New("invalid position")
+
+func scorecardNotation(ps scoring.Scorecard) string {
+	if ps.CatMask == 0 {
+		return "-"
+	}
+	var entries []string
+	for c := range scoring.Category(scoring.Categories) {
+		if ps.CatMask&(1<<c) != 0 {
+			entries = append(entries, fmt.Sprintf("%s:%d", c.Code(), ps.ScoresByCategory[c]))
+		}
+	}
+	return strings.Join(entries, ",")
+}
+
+func parseScorecard(s string) (scoring.Scorecard, error) {
+	var ps scoring.Scorecard
+	if s == "-" {
+		return ps, nil
+	}
+	for _, entry := range strings.Split(s, ",") {
+		code, scoreStr, ok := strings.Cut(entry, ":")
+		if !ok {
+			return ps, fmt.Errorf("%w: scorecard entry %q is not code:score", ErrInvalidPosition, entry)
+		}
+		c, ok := scoring.ParseCatCode(code)
+		if !ok {
+			return ps, fmt.Errorf("%w: unknown category code %q", ErrInvalidPosition, code)
+		}
+		if ps.CatMask&(1<<c) != 0 {
+			return ps, fmt.Errorf("%w: category %q filled twice", ErrInvalidPosition, code)
+		}
+		score, err := strconv.ParseUint(scoreStr, 10, 16)
+		if err != nil {
+			return ps, fmt.Errorf("%w: score %q for %q: %v", ErrInvalidPosition, scoreStr, code, err)
+		}
+		if !scoring.ValidCategoryScore(c, uint16(score)) {
+			return ps, fmt.Errorf("%w: %d is not a possible %s score", ErrInvalidPosition, score, c)
+		}
+		ps.ScoresByCategory[c] = uint16(score)
+		ps.CatMask |= 1 << c
+	}
+	return ps, nil
+}
+
+func parseRollNotation(s string) (dice.Roll, error) {
+	if len(s) != 5 {
+		return 0, fmt.Errorf("%w: roll %q must have 5 dice", ErrInvalidPosition, s)
+	}
+	var d [5]dice.Die
+	for i := range d {
+		if s[i] < '1' || s[i] > '6' {
+			return 0, fmt.Errorf("%w: roll %q has invalid die %q", ErrInvalidPosition, s, s[i])
+		}
+		d[i] = dice.Die(s[i] - '0')
+	}
+	return dice.RollOf(d), nil
+}
+
+// Notation formats the position of g in position notation.
+func (g *Game) Notation() string {
+	cards := make([]string, len(g.Scorecards))
+	for i, ps := range g.Scorecards {
+		cards[i] = scorecardNotation(ps)
+	}
+	dice := "-"
+	if g.CurTurn.RollCnt > 0 {
+		dice = g.CurTurn.CurrentRoll.Notation()
+	}
+	return fmt.Sprintf("%s %s %d %d", strings.Join(cards, "/"), dice, g.CurTurn.RollCnt, g.CurPlayerIdx+1)
+}
+
+// ParsePosition parses a position in position notation. The returned game
+// has no players or rng set.
+func ParsePosition(s string) (*Game, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("%w: want 4 fields, got %d", ErrInvalidPosition, len(fields))
+	}
+
+	var scorecards []scoring.Scorecard
+	for _, card := range strings.Split(fields[0], "/") {
+		ps, err := parseScorecard(card)
+		if err != nil {
+			return nil, err
+		}
+		scorecards = append(scorecards, ps)
+	}
+
+	rollCnt, err := strconv.Atoi(fields[2])
+	if err != nil || rollCnt < 0 || rollCnt > scoring.MaxReRolls {
+		return nil, fmt.Errorf("%w: rolls %q must be between 0 and %d", ErrInvalidPosition, fields[2], scoring.MaxReRolls)
+	}
+	t := &Turn{RollCnt: rollCnt}
+	switch {
+	case rollCnt == 0 && fields[1] != "-":
+		return nil, fmt.Errorf("%w: dice %q given before the first roll", ErrInvalidPosition, fields[1])
+	case rollCnt > 0:
+		if t.CurrentRoll, err = parseRollNotation(fields[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	playerNum, err := strconv.Atoi(fields[3])
+	if err != nil || playerNum < 1 || playerNum > len(scorecards) {
+		return nil, fmt.Errorf("%w: player %q must be between 1 and %d", ErrInvalidPosition, fields[3], len(scorecards))
+	}
+
+	g := &Game{
+		Scorecards:   scorecards,
+		CurTurn:      t,
+		CurPlayerIdx: playerNum - 1,
+		moveBuf:      make([]Move, 0, MaxMoves),
+	}
+	if err := g.validateTurnOrder(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPosition, err)
+	}
+	return g, nil
+}
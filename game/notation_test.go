@@ -0,0 +1,135 @@
+package game
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/scoring"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestPositionNotationRoundTrip(t *testing.T) {
+	opts := []cmp.Option{
+		cmp.AllowUnexported(Game{}, Turn{}, scoring.Scorecard{}),
+		cmpopts.IgnoreFields(Game{}, "moveBuf"),
+	}
+	for _, tt := range []struct {
+		s string
+		g *Game
+	}{
+		{
+			s: "-/- - 0 1",
+			g: &Game{
+				Scorecards: []scoring.Scorecard{{}, {}},
+				CurTurn:    &Turn{},
+			},
+		},
+		{
+			s: "ss:30/- 55521 1 2",
+			g: &Game{
+				Scorecards: []scoring.Scorecard{
+					{
+						ScoresByCategory: [13]uint16{scoring.CAT_SMALL_STRAIGHT: 30},
+						CatMask:          1 << scoring.CAT_SMALL_STRAIGHT,
+					},
+					{},
+				},
+				CurTurn: &Turn{
+					CurrentRoll: dice.RollOf([5]dice.Die{dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_TWO, dice.DIE_ONE}),
+					RollCnt:     1,
+				},
+				CurPlayerIdx: 1,
+			},
+		},
+		{
+			s: "1s:3,6s:0,fh:25,yz:150 66666 3 1",
+			g: &Game{
+				Scorecards: []scoring.Scorecard{
+					{
+						ScoresByCategory: [13]uint16{
+							scoring.CAT_ONES:       3,
+							scoring.CAT_FULL_HOUSE: 25,
+							scoring.CAT_YATZY:      150,
+						},
+						CatMask: 1<<scoring.CAT_ONES | 1<<scoring.CAT_SIXES | 1<<scoring.CAT_FULL_HOUSE | 1<<scoring.CAT_YATZY,
+					},
+				},
+				CurTurn: &Turn{
+					CurrentRoll: dice.RollOf([5]dice.Die{dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX}),
+					RollCnt:     3,
+				},
+			},
+		},
+	} {
+		got, err := ParsePosition(tt.s)
+		if err != nil {
+			t.Errorf("ParsePosition(%q) returned unexpected error: %v", tt.s, err)
+			continue
+		}
+		if diff := cmp.Diff(got, tt.g, opts...); diff != "" {
+			t.Errorf("ParsePosition(%q) does not match (-got, +want):\n%s", tt.s, diff)
+		}
+		if s := got.Notation(); s != tt.s {
+			t.Errorf("Notation() = %q; want %q", s, tt.s)
+		}
+	}
+}
+
+func TestPositionNotationRoundTripPlayouts(t *testing.T) {
+	opts := []cmp.Option{
+		cmp.AllowUnexported(Game{}, Turn{}, scoring.Scorecard{}),
+		cmp.FilterPath(func(p cmp.Path) bool {
+			f, ok := p.Last().(cmp.StructField)
+			return ok && (f.Name() == "Players" || f.Name() == "Rng" || f.Name() == "Src" || f.Name() == "moveBuf")
+		}, cmp.Ignore()),
+	}
+	g := New(rand.NewPCG(1, 2), make([]Player, 3))
+	r := g.Rng
+	for gameOver := false; !gameOver; {
+		if g.CurTurn.RollCnt == 0 {
+			g.CurTurn.CurrentRoll = g.RandRoll()
+			g.CurTurn.RollCnt = 1
+		}
+		s := g.Notation()
+		got, err := ParsePosition(s)
+		if err != nil {
+			t.Fatalf("ParsePosition(%q) returned unexpected error: %v", s, err)
+		}
+		if diff := cmp.Diff(got, g, opts...); diff != "" {
+			t.Fatalf("ParsePosition(%q) does not round trip (-got, +want):\n%s", s, diff)
+		}
+		moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+		gameOver = g.DoMove(moves[r.IntN(len(moves))])
+	}
+}
+
+func TestParsePositionErrors(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"- - 0",
+		"- - 0 1 extra",
+		"xx:3 - 0 1",
+		"1s - 0 1",
+		"1s:a - 0 1",
+		"1s:3,1s:3 - 0 1",
+		"1s:65535 - 0 1",
+		"1s:6 - 0 1",
+		"ls:39 - 0 1",
+		"- 1234 1 1",
+		"- 12347 1 1",
+		"- 12345 0 1",
+		"- - 4 1",
+		"- 12345 1 0",
+		"-/- 12345 1 3",
+		"1s:3/- 66621 1 1",
+		"-/1s:3 - 0 2",
+		"1s:3,2s:6,3s:9,4s:12,5s:15,6s:18,3k:20,4k:20,fh:25,ss:30,ls:40,ch:20,yz:50 66666 1 1",
+	} {
+		if _, err := ParsePosition(s); !errors.Is(err, ErrInvalidPosition) {
+			t.Errorf("ParsePosition(%q) = %v; want %v", s, err, ErrInvalidPosition)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package game
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+// MovePolicy is a player whose choices can be enumerated, so the outcome
+// of its play can be computed exactly.
+type MovePolicy interface {
+	Player
+	// MoveProbabilities sets probs[i] to the probability of picking
+	// moves[i] for the current player of g.
+	MoveProbabilities(g *Game, moves []Move, probs []float64)
+}
+
+// maxExactTurns is the most turns left for which FinalScoreDistribution
+// enumerates every outcome rather than sampling.
+const maxExactTurns = 3
+
+// ScoreDistribution is the distribution of a final score.
+type ScoreDistribution struct {
+	// Scores is the probability of every possible score, lowest first.
+	Scores []scoring.ScoreProbability
+	// Samples is the number of games played to estimate it, or 0 if it
+	// is exact.
+	Samples int
+}
+
+func newScoreDistribution(probs map[uint16]float64, samples int) ScoreDistribution {
+	d := ScoreDistribution{Samples: samples}
+	for score, p := range probs {
+		d.Scores = append(d.Scores, scoring.ScoreProbability{Score: score, P: p})
+	}
+	slices.SortFunc(d.Scores, func(a, b scoring.ScoreProbability) int {
+		return cmp.Compare(a.Score, b.Score)
+	})
+	return d
+}
+
+// Mean returns the mean score, or 0 if d is empty.
+func (d ScoreDistribution) Mean() float64 {
+	var m float64
+	for _, sp := range d.Scores {
+		m += float64(sp.Score) * sp.P
+	}
+	return m
+}
+
+// Quantile returns the lowest score s such that the probability of
+// scoring at most s is at least q, or 0 if d is empty.
+func (d ScoreDistribution) Quantile(q float64) uint16 {
+	if len(d.Scores) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, sp := range d.Scores {
+		sum += sp.P
+		if sum >= q-1e-12 {
+			return sp.Score
+		}
+	}
+	return d.Scores[len(d.Scores)-1].Score
+}
+
+// AtLeast returns the probability of scoring x or more.
+func (d ScoreDistribution) AtLeast(x uint16) float64 {
+	var p float64
+	for _, sp := range d.Scores {
+		if sp.Score >= x {
+			p += sp.P
+		}
+	}
+	return p
+}
+
+// WriteHistogram writes the distribution as a text histogram with a bar
+// for every bin of binWidth scores, leaving out negligible bins at either
+// end. It writes nothing if d is empty.
+func (d ScoreDistribution) WriteHistogram(w io.Writer, binWidth uint16) error {
+	const barWidth = 50
+	if len(d.Scores) == 0 {
+		return nil
+	}
+	lo := d.Scores[0].Score / binWidth
+	hi := d.Scores[len(d.Scores)-1].Score / binWidth
+	bins := make([]float64, hi-lo+1)
+	for _, sp := range d.Scores {
+		bins[sp.Score/binWidth-lo] += sp.P
+	}
+	// Leave out the long tails that would round to nothing, such as the
+	// rare games with several yatzy bonuses.
+	const minShown = 0.0005
+	for len(bins) > 1 && bins[0] < minShown {
+		bins = bins[1:]
+		lo++
+	}
+	for len(bins) > 1 && bins[len(bins)-1] < minShown {
+		bins = bins[:len(bins)-1]
+	}
+	peak := slices.Max(bins)
+	for i, p := range bins {
+		start := (lo + uint16(i)) * binWidth
+		label := fmt.Sprintf("%d-%d", start, start+binWidth-1)
+		bar := strings.Repeat("#", int(math.Round(barWidth*p/peak)))
+		if _, err := fmt.Fprintf(w, "%9s │%-*s %5.1f%%\n", label, barWidth, bar, 100*p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FinalScoreDistribution returns the distribution of the final score of
+// the current player of g when every player plays as g's players do. It
+// is exact if the player plays alone, chooses moves with a MovePolicy and
+// has at most maxExactTurns turns left. Otherwise it is estimated from
+// samples games, stopping early if ctx is done. Games cut short by ctx
+// are not counted, so the distribution is empty if none finished.
+func (g *Game) FinalScoreDistribution(ctx context.Context, samples int) ScoreDistribution {
+	pIdx := g.CurPlayerIdx
+	ps := g.Scorecards[pIdx]
+	if pol, ok := g.Players[pIdx].(MovePolicy); ok && len(g.Scorecards) == 1 && ps.GetTurnsLeft() <= maxExactTurns {
+		e := &exactScores{pol: pol, finals: make(map[scoring.Scorecard]map[uint16]float64)}
+		probs := make(map[uint16]float64)
+		if g.CurTurn.RollCnt == 0 {
+			e.addFinals(probs, 1, ps)
+		} else {
+			t := newTurnOutcomes(ps, pol)
+			for next, p := range t.roll(g.CurTurn.CurrentRoll.Counts().Key(), g.CurTurn.RollCnt) {
+				e.addFinals(probs, p, next)
+			}
+		}
+		return newScoreDistribution(probs, 0)
+	}
+
+	counts := make(map[uint16]float64)
+	var played int
+	for range samples {
+		if ctx.Err() != nil {
+			break
+		}
+		sg := g.Clone()
+		for sg.CurTurn.RollCnt > 0 && !sg.IsOver() {
+			moves := sg.GetMovesForCurrentPlayer(sg.CurTurn.CurrentRoll, sg.moveBuf[:0])
+			sg.DoMove(moves[sg.Players[pIdx].PickMove(ctx, sg, moves)])
+		}
+		if !sg.IsOver() {
+			sg.RunSimulation(ctx)
+		}
+		if !sg.IsOver() {
+			break
+		}
+		counts[sg.Scorecards[pIdx].Score()]++
+		played++
+	}
+	for score := range counts {
+		counts[score] /= float64(played)
+	}
+	return newScoreDistribution(counts, played)
+}
+
+// exactScores enumerates the final scores of a scorecard played by pol.
+type exactScores struct {
+	pol    MovePolicy
+	finals map[scoring.Scorecard]map[uint16]float64
+}
+
+// addFinals adds the distribution of the final score of ps, weighted by
+// p, to probs.
+func (e *exactScores) addFinals(probs map[uint16]float64, p float64, ps scoring.Scorecard) {
+	for score, q := range e.finalScores(ps) {
+		probs[score] += p * q
+	}
+}
+
+func (e *exactScores) finalScores(ps scoring.Scorecard) map[uint16]float64 {
+	if ps.CatMask == scoring.AllFilled {
+		return map[uint16]float64{ps.Score(): 1}
+	}
+	if probs, ok := e.finals[ps]; ok {
+		return probs
+	}
+	probs := make(map[uint16]float64)
+	for next, p := range newTurnOutcomes(ps, e.pol).hold(0, 0) {
+		e.addFinals(probs, p, next)
+	}
+	e.finals[ps] = probs
+	return probs
+}
+
+// turnOutcomes computes the distribution of the scorecard at the end of
+// a turn. The outcome from a roll only depends on the roll and how many
+// times the dice have been rolled, and from rerolling a hold only on the
+// hold and the same, so both are cached.
+type turnOutcomes struct {
+	ps    scoring.Scorecard
+	g     *Game // a game with only ps, to generate moves in
+	pol   MovePolicy
+	rolls [scoring.MaxReRolls + 1][dice.DistinctRolls]map[scoring.Scorecard]float64
+	holds [scoring.MaxReRolls][dice.DistinctHolds]map[scoring.Scorecard]float64
+}
+
+func newTurnOutcomes(ps scoring.Scorecard, pol MovePolicy) *turnOutcomes {
+	return &turnOutcomes{
+		ps:  ps,
+		g:   &Game{Scorecards: []scoring.Scorecard{ps}, CurTurn: &Turn{}},
+		pol: pol,
+	}
+}
+
+// roll returns the outcomes after rolling the roll with key for the
+// rollCnt time this turn.
+func (t *turnOutcomes) roll(key, rollCnt int) map[scoring.Scorecard]float64 {
+	if out := t.rolls[rollCnt][key]; out != nil {
+		return out
+	}
+	r := dice.Multiset(key).Roll()
+	*t.g.CurTurn = Turn{CurrentRoll: r, RollCnt: rollCnt}
+	moves := t.g.GetMovesForCurrentPlayer(r, nil)
+	probs := make([]float64, len(moves))
+	t.pol.MoveProbabilities(t.g, moves, probs)
+
+	out := make(map[scoring.Scorecard]float64)
+	for i, m := range moves {
+		if probs[i] == 0 {
+			continue
+		}
+		if !m.Reroll {
+			out[t.ps.Update(r, m.Cat)] += probs[i]
+			continue
+		}
+		for next, p := range t.hold(m.Hold, rollCnt) {
+			out[next] += probs[i] * p
+		}
+	}
+	t.rolls[rollCnt][key] = out
+	return out
+}
+
+// hold returns the outcomes after rerolling the dice not in hold, when the
+// dice have been rolled rollCnt times this turn.
+func (t *turnOutcomes) hold(hold dice.Counts, rollCnt int) map[scoring.Scorecard]float64 {
+	key := hold.Key()
+	if out := t.holds[rollCnt][key]; out != nil {
+		return out
+	}
+	out := make(map[scoring.Scorecard]float64)
+	for _, o := range dice.RerollDistribution(hold) {
+		for next, p := range t.roll(int(o.Key), rollCnt+1) {
+			out[next] += o.P * p
+		}
+	}
+	t.holds[rollCnt][key] = out
+	return out
+}
@@ -0,0 +1,149 @@
+package game
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/scoring"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestScoreDistribution(t *testing.T) {
+	d := newScoreDistribution(map[uint16]float64{10: 0.25, 20: 0.5, 35: 0.25}, 0)
+	if got, want := d.Mean(), 21.25; got != want {
+		t.Errorf("mean() = %v; want %v", got, want)
+	}
+	for _, tt := range []struct {
+		q    float64
+		want uint16
+	}{
+		{0, 10},
+		{0.25, 10},
+		{0.5, 20},
+		{0.75, 20},
+		{0.9, 35},
+		{1, 35},
+	} {
+		if got := d.Quantile(tt.q); got != tt.want {
+			t.Errorf("quantile(%v) = %d; want %d", tt.q, got, tt.want)
+		}
+	}
+	for _, tt := range []struct {
+		x    uint16
+		want float64
+	}{
+		{0, 1},
+		{10, 1},
+		{11, 0.75},
+		{35, 0.25},
+		{36, 0},
+	} {
+		if got := d.AtLeast(tt.x); got != tt.want {
+			t.Errorf("atLeast(%d) = %v; want %v", tt.x, got, tt.want)
+		}
+	}
+
+	var b strings.Builder
+	if err := d.WriteHistogram(&b, 10); err != nil {
+		t.Fatalf("writeHistogram() returned unexpected error: %v", err)
+	}
+	want := []string{
+		"    10-19 │#########################                           25.0%",
+		"    20-29 │##################################################  50.0%",
+		"    30-39 │#########################                           25.0%",
+	}
+	if diff := cmp.Diff(strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n"), want); diff != "" {
+		t.Errorf("histogram does not match (-got, +want):\n%s", diff)
+	}
+}
+
+func TestScoreDistributionEmpty(t *testing.T) {
+	var d ScoreDistribution
+	if got := d.Mean(); got != 0 {
+		t.Errorf("mean() = %v; want 0", got)
+	}
+	if got := d.Quantile(0.5); got != 0 {
+		t.Errorf("quantile(0.5) = %d; want 0", got)
+	}
+	var b strings.Builder
+	if err := d.WriteHistogram(&b, 10); err != nil {
+		t.Fatalf("writeHistogram() returned unexpected error: %v", err)
+	}
+	if b.Len() != 0 {
+		t.Errorf("writeHistogram() wrote %q; want nothing", b.String())
+	}
+}
+
+func TestFinalScoreDistributionExact(t *testing.T) {
+	opts := []cmp.Option{
+		cmp.AllowUnexported(ScoreDistribution{}, scoring.ScoreProbability{}),
+		cmpopts.EquateApprox(0, 1e-12),
+	}
+	for _, tt := range []struct {
+		name string
+		ps   scoring.Scorecard
+		t    Turn
+		want []scoring.ScoreProbability
+	}{
+		{
+			name: "game over",
+			ps:   scoring.Scorecard{ScoresByCategory: [13]uint16{scoring.CAT_CHANCE: 20}, CatMask: scoring.AllFilled},
+			want: []scoring.ScoreProbability{{Score: 20, P: 1}},
+		},
+		{
+			name: "last roll",
+			ps:   scoring.Scorecard{ScoresByCategory: [13]uint16{scoring.CAT_FIVES: 5}, CatMask: scoring.AllFilled &^ (1 << scoring.CAT_CHANCE)},
+			t:    Turn{CurrentRoll: dice.RollOf([5]dice.Die{dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX, dice.DIE_ONE}), RollCnt: scoring.MaxReRolls},
+			want: []scoring.ScoreProbability{{Score: 30, P: 1}},
+		},
+	} {
+		g := New(rand.NewPCG(1, 2), make([]Player, 1))
+		g.Players[0] = &randomPlayer{g.Rng}
+		g.Scorecards[0] = tt.ps
+		*g.CurTurn = tt.t
+		got := g.FinalScoreDistribution(context.Background(), 0)
+		if got.Samples != 0 {
+			t.Errorf("%s: distribution was sampled; want exact", tt.name)
+		}
+		var sum float64
+		for _, sp := range got.Scores {
+			sum += sp.P
+		}
+		if math.Abs(sum-1) > 1e-9 {
+			t.Errorf("%s: probabilities sum to %v; want 1", tt.name, sum)
+		}
+		if diff := cmp.Diff(got.Scores, tt.want, opts...); diff != "" {
+			t.Errorf("%s: scores do not match (-got, +want):\n%s", tt.name, diff)
+		}
+	}
+}
+
+// TestFinalScoreDistributionSampled checks that sampling agrees with
+// enumerating every outcome.
+func TestFinalScoreDistributionSampled(t *testing.T) {
+	g := New(rand.NewPCG(1, 2), make([]Player, 1))
+	g.Players[0] = &randomPlayer{g.Rng}
+	g.Scorecards[0].CatMask = scoring.AllFilled &^ (1<<scoring.CAT_CHANCE | 1<<scoring.CAT_FULL_HOUSE)
+	exact := g.FinalScoreDistribution(context.Background(), 0)
+
+	// An opponent forces sampling.
+	g.Scorecards = append(g.Scorecards, g.Scorecards[0])
+	g.Players = append(g.Players, &randomPlayer{g.Rng})
+	const n = 20000
+	sampled := g.FinalScoreDistribution(context.Background(), n)
+	if sampled.Samples != n {
+		t.Fatalf("distribution was sampled from %d games; want %d", sampled.Samples, n)
+	}
+	for _, x := range []uint16{10, 20, 30, 40} {
+		p := exact.AtLeast(x)
+		stderr := math.Sqrt(p * (1 - p) / n)
+		if got := sampled.AtLeast(x); math.Abs(got-p) > 5*stderr+1e-9 {
+			t.Errorf("sampled atLeast(%d) = %v; want %v ± %v", x, got, p, stderr)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+// Errors returned when a move is not legal in the current game state.
+// Validation errors wrap one of these, so callers should compare with
+// errors.Is.
+var (
+	ErrGameOver         = errors.New("game is over")
+	ErrNotRolled        = errors.New("dice have not been rolled this turn")
+	ErrCategoryUsed     = errors.New("category has already been used")
+	ErrInvalidSelection = errors.New("invalid category selection")
+	ErrUnknownMove      = errors.New("unknown move")
+)
+
+// Errors from the dice and scoring packages that validation also returns,
+// so callers only need this package to check for them.
+var (
+	ErrInvalidHold   = dice.ErrInvalidHold
+	ErrHoldNotInRoll = dice.ErrHoldNotInRoll
+	ErrEmptyHold     = dice.ErrEmptyHold
+	ErrInvalidDie    = dice.ErrInvalidDie
+	ErrNoRerollsLeft = scoring.ErrNoRerollsLeft
+)
+
+// ErrTurnOrder is returned for a game state that players taking turns in
+// order cannot reach.
+var ErrTurnOrder = errors.New("players are out of turn")
+
+// NewRerollMove creates a move that rerolls every die not in hold.
+func NewRerollMove(hold ...dice.Die) Move {
+	return Move{
+		Hold:   dice.Count(hold...),
+		Reroll: true,
+	}
+}
+
+// SelectMove creates a move that selects category c for the current
+// roll of the current player.
+func (g *Game) SelectMove(c scoring.Category) Move {
+	m := Move{Cat: c}
+	if c < scoring.Categories {
+		next := g.Scorecards[g.CurPlayerIdx].Update(g.CurTurn.CurrentRoll, c)
+		m.Score = next.ScoresByCategory[c]
+	}
+	return m
+}
+
+func (g *Game) IsOver() bool {
+	for _, ps := range g.Scorecards {
+		if ps.CatMask != scoring.AllFilled {
+			return false
+		}
+	}
+	return true
+}
+
+// validateTurnOrder checks that g could be reached by its players taking
+// turns in order: players before the current one have filled one more
+// category than it, and the rest as many. A finished game has no turn
+// underway and is back to the first player.
+func (g *Game) validateTurnOrder() error {
+	if g.IsOver() {
+		if g.CurPlayerIdx != 0 || g.CurTurn.RollCnt != 0 {
+			return fmt.Errorf("%w: game is over but player %d is rolling", ErrTurnOrder, g.CurPlayerIdx+1)
+		}
+		return nil
+	}
+	filled := scoring.Categories - g.Scorecards[g.CurPlayerIdx].GetTurnsLeft()
+	if filled == scoring.Categories {
+		return fmt.Errorf("%w: player %d has no categories left", ErrTurnOrder, g.CurPlayerIdx+1)
+	}
+	for i, ps := range g.Scorecards {
+		want := filled
+		if i < g.CurPlayerIdx {
+			want++
+		}
+		if got := scoring.Categories - ps.GetTurnsLeft(); got != want {
+			return fmt.Errorf("%w: player %d has filled %d categories; want %d", ErrTurnOrder, i+1, got, want)
+		}
+	}
+	return nil
+}
+
+// ValidateMove checks that m is legal for the current player given the
+// current turn. Moves generated by GetMovesForCurrentPlayer are always
+// legal, so this is only needed for moves supplied from outside the engine.
+func (g *Game) ValidateMove(m Move) error {
+	if g.IsOver() {
+		return ErrGameOver
+	}
+	if g.CurTurn.RollCnt == 0 {
+		return ErrNotRolled
+	}
+	if m.Reroll {
+		if g.CurTurn.RollCnt >= scoring.MaxReRolls {
+			return fmt.Errorf("%w: already rolled %d times", ErrNoRerollsLeft, g.CurTurn.RollCnt)
+		}
+		return dice.ValidateHold(g.CurTurn.CurrentRoll, m.Hold)
+	}
+
+	if m.Cat >= scoring.Categories {
+		return fmt.Errorf("%w: unknown category %d", ErrInvalidSelection, m.Cat)
+	}
+	ps := g.Scorecards[g.CurPlayerIdx]
+	if ps.CatMask&(1<<m.Cat) != 0 {
+		return fmt.Errorf("%w: %s", ErrCategoryUsed, m.Cat)
+	}
+	next := ps.Update(g.CurTurn.CurrentRoll, m.Cat)
+	if score := next.ScoresByCategory[m.Cat]; m.Score != score {
+		return fmt.Errorf("%w: %s scores %d for roll %s, not %d", ErrInvalidSelection, m.Cat, score, g.CurTurn.CurrentRoll, m.Score)
+	}
+	return nil
+}
+
+// Play validates m and then applies it so that it can be undone. Returns
+// whether the game is over.
+func (g *Game) Play(m Move) (bool, error) {
+	if err := g.ValidateMove(m); err != nil {
+		return false, err
+	}
+	g.record(false, 0)
+	return g.DoMove(m), nil
+}
@@ -0,0 +1,146 @@
+package game
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+func TestGameValidateMove(t *testing.T) {
+	roll := dice.RollOf([5]dice.Die{dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_TWO, dice.DIE_ONE})
+	newTestGame := func(rollCnt int) *Game {
+		return &Game{
+			CurTurn: &Turn{
+				CurrentRoll: roll,
+				RollCnt:     rollCnt,
+			},
+			Scorecards: []scoring.Scorecard{
+				{
+					ScoresByCategory: [13]uint16{
+						scoring.CAT_FIVES: 15,
+					},
+					CatMask: 1 << scoring.CAT_FIVES,
+				},
+			},
+		}
+	}
+
+	for _, tt := range []struct {
+		name    string
+		rollCnt int
+		move    func(g *Game) Move
+		want    error
+	}{
+		{
+			name:    "reroll",
+			rollCnt: 1,
+			move:    func(*Game) Move { return NewRerollMove(dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_FIVE) },
+		},
+		{
+			name:    "reroll nothing held",
+			rollCnt: 2,
+			move:    func(*Game) Move { return NewRerollMove() },
+			want:    ErrEmptyHold,
+		},
+		{
+			name:    "reroll after last roll",
+			rollCnt: scoring.MaxReRolls,
+			move:    func(*Game) Move { return NewRerollMove(dice.DIE_FIVE) },
+			want:    ErrNoRerollsLeft,
+		},
+		{
+			name:    "reroll before first roll",
+			rollCnt: 0,
+			move:    func(*Game) Move { return NewRerollMove(dice.DIE_FIVE) },
+			want:    ErrNotRolled,
+		},
+		{
+			name:    "hold die not in roll",
+			rollCnt: 1,
+			move:    func(*Game) Move { return NewRerollMove(dice.DIE_SIX) },
+			want:    ErrHoldNotInRoll,
+		},
+		{
+			name:    "hold more dice than rolled",
+			rollCnt: 1,
+			move:    func(*Game) Move { return NewRerollMove(dice.DIE_TWO, dice.DIE_TWO) },
+			want:    ErrHoldNotInRoll,
+		},
+		{
+			name:    "hold all dice",
+			rollCnt: 1,
+			move: func(*Game) Move {
+				return NewRerollMove(dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_TWO, dice.DIE_ONE)
+			},
+			want: ErrInvalidHold,
+		},
+		{
+			name:    "hold unset die",
+			rollCnt: 1,
+			move:    func(*Game) Move { return NewRerollMove(dice.DIE_FIVE, dice.DIE_UNSET) },
+			want:    ErrInvalidDie,
+		},
+		{
+			name:    "select",
+			rollCnt: 3,
+			move:    func(g *Game) Move { return g.SelectMove(scoring.CAT_THREE_OF_A_KIND) },
+		},
+		{
+			name:    "select used category",
+			rollCnt: 1,
+			move:    func(g *Game) Move { return g.SelectMove(scoring.CAT_FIVES) },
+			want:    ErrCategoryUsed,
+		},
+		{
+			name:    "select with wrong score",
+			rollCnt: 1,
+			move: func(g *Game) Move {
+				m := g.SelectMove(scoring.CAT_CHANCE)
+				m.Score = 30
+				return m
+			},
+			want: ErrInvalidSelection,
+		},
+		{
+			name:    "select unknown category",
+			rollCnt: 1,
+			move:    func(g *Game) Move { return g.SelectMove(scoring.Categories) },
+			want:    ErrInvalidSelection,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(tt.rollCnt)
+			err := g.ValidateMove(tt.move(g))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("ValidateMove() = %v; want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGameValidateMoveGameOver(t *testing.T) {
+	g := &Game{
+		CurTurn:    &Turn{RollCnt: 1},
+		Scorecards: []scoring.Scorecard{{CatMask: scoring.AllFilled}},
+	}
+	if err := g.ValidateMove(NewRerollMove()); !errors.Is(err, ErrGameOver) {
+		t.Errorf("ValidateMove() = %v; want %v", err, ErrGameOver)
+	}
+}
+
+func TestGameGetMovesForCurrentPlayerAreValid(t *testing.T) {
+	g := &Game{
+		CurTurn: &Turn{
+			CurrentRoll: dice.RollOf([5]dice.Die{dice.DIE_SIX, dice.DIE_SIX, dice.DIE_FOUR, dice.DIE_THREE, dice.DIE_ONE}),
+			RollCnt:     1,
+		},
+		Scorecards: []scoring.Scorecard{{CatMask: 1 << scoring.CAT_SIXES}},
+	}
+	for _, m := range g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil) {
+		if err := g.ValidateMove(m); err != nil {
+			t.Errorf("generated move %s is invalid: %v", m, err)
+		}
+	}
+}
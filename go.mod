@@ -4,7 +4,6 @@ go 1.23.0
 
 toolchain go1.23.7
 
-require (
-	github.com/google/go-cmp v0.7.0 // indirect
-	golang.org/x/text v0.23.0 // indirect
-)
+require github.com/google/go-cmp v0.7.0
+
+require golang.org/x/text v0.23.0 // indirect
@@ -0,0 +1,178 @@
+// Package render draws scorecards as text tables.
+package render
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+// Style is the characters a table is drawn with.
+type Style struct {
+	// Horizontal and Vertical draw the lines of the table. Top, Inner and
+	// Bottom are the left, middle and right joins of the top line, the
+	// lines between sections and the bottom line.
+	Horizontal, Vertical string
+	Top, Inner, Bottom   [3]string
+	// Unfilled marks a category that has not been filled yet and
+	// Scratched one filled with 0.
+	Unfilled, Scratched string
+}
+
+var (
+	// ASCII draws tables with plain ASCII, for any terminal.
+	ASCII = Style{
+		Horizontal: "-",
+		Vertical:   "|",
+		Top:        [3]string{"+", "+", "+"},
+		Inner:      [3]string{"+", "+", "+"},
+		Bottom:     [3]string{"+", "+", "+"},
+		Unfilled:   ".",
+		Scratched:  "x",
+	}
+	// Unicode draws tables with box drawing characters.
+	Unicode = Style{
+		Horizontal: "━",
+		Vertical:   "┃",
+		Top:        [3]string{"┏", "┳", "┓"},
+		Inner:      [3]string{"┣", "╋", "┫"},
+		Bottom:     [3]string{"┗", "┻", "┛"},
+		Unfilled:   "·",
+		Scratched:  "✗",
+	}
+)
+
+// minColWidth is the least width of a player's column, enough for any
+// score or bonus progress.
+const minColWidth = 6
+
+// row is a line of the table: a label and how to show it for a player.
+type row struct {
+	label string
+	cell  func(ps scoring.Scorecard) string
+}
+
+// Scorecards draws cards side by side, one column for each player headed
+// by their name, or "player N" past the end of names, in style. Below the categories of each section are
+// the upper section total, the progress towards the upper section bonus
+// and the bonus itself, or the chance of earning it while that is
+// undecided; then the yatzy bonuses, the lower section total and the
+// grand total.
+func Scorecards(style Style, names []string, cards []scoring.Scorecard) string {
+	sections := [][]row{{{label: "", cell: nil}}}
+	var upper, lower []row
+	for c := range scoring.Category(scoring.Categories) {
+		r := row{label: c.String(), cell: func(ps scoring.Scorecard) string {
+			return style.category(ps, c)
+		}}
+		if c <= scoring.CAT_SIXES {
+			upper = append(upper, r)
+		} else {
+			lower = append(lower, r)
+		}
+	}
+	lower = append(lower, row{label: "yatzy bonus", cell: func(ps scoring.Scorecard) string {
+		if ps.CatMask&(1<<scoring.CAT_YATZY) == 0 {
+			return style.Unfilled
+		}
+		return fmt.Sprint(ps.YatzyBonusSum())
+	}})
+	sections = append(sections,
+		upper,
+		[]row{
+			{label: "upper total", cell: func(ps scoring.Scorecard) string { return fmt.Sprint(ps.UpperSum()) }},
+			{label: "bonus progress", cell: func(ps scoring.Scorecard) string {
+				return fmt.Sprintf("%d/%d", min(ps.UpperSum(), scoring.UpperSectionMinBonusSum), scoring.UpperSectionMinBonusSum)
+			}},
+			{label: "upper bonus", cell: func(ps scoring.Scorecard) string { return ps.BonusString() }},
+		},
+		lower,
+		[]row{
+			{label: "lower total", cell: func(ps scoring.Scorecard) string { return fmt.Sprint(ps.LowerSum()) }},
+			{label: "total", cell: func(ps scoring.Scorecard) string { return fmt.Sprint(ps.Score()) }},
+		},
+	)
+
+	heads := make([]string, len(cards))
+	for i := range heads {
+		if i < len(names) {
+			heads[i] = names[i]
+		} else {
+			heads[i] = fmt.Sprintf("player %d", i+1)
+		}
+	}
+
+	// Work out every cell first so the columns can be sized to fit.
+	cells := make([][][]string, len(sections))
+	labelWidth := 0
+	colWidths := make([]int, len(cards))
+	for i, name := range heads {
+		colWidths[i] = max(minColWidth, utf8.RuneCountInString(name))
+	}
+	for s, section := range sections {
+		cells[s] = make([][]string, len(section))
+		for r, row := range section {
+			labelWidth = max(labelWidth, utf8.RuneCountInString(row.label))
+			cells[s][r] = make([]string, len(cards))
+			for i, ps := range cards {
+				cell := heads[i]
+				if row.cell != nil {
+					cell = row.cell(ps)
+				}
+				cells[s][r][i] = cell
+				colWidths[i] = max(colWidths[i], utf8.RuneCountInString(cell))
+			}
+		}
+	}
+
+	var b strings.Builder
+	line := func(joins [3]string) {
+		b.WriteString(joins[0])
+		b.WriteString(strings.Repeat(style.Horizontal, labelWidth+2))
+		for _, w := range colWidths {
+			b.WriteString(joins[1])
+			b.WriteString(strings.Repeat(style.Horizontal, w+2))
+		}
+		b.WriteString(joins[2])
+		b.WriteByte('\n')
+	}
+	line(style.Top)
+	for s, section := range sections {
+		if s > 0 {
+			line(style.Inner)
+		}
+		for r, row := range section {
+			fmt.Fprintf(&b, "%s %-*s ", style.Vertical, labelWidth, row.label)
+			for i, cell := range cells[s][r] {
+				// Names are centred over their column and scores right
+				// aligned.
+				if row.cell == nil {
+					pad := colWidths[i] - utf8.RuneCountInString(cell)
+					cell = strings.Repeat(" ", pad/2) + cell + strings.Repeat(" ", pad-pad/2)
+				}
+				fmt.Fprintf(&b, "%s %*s ", style.Vertical, colWidths[i], cell)
+			}
+			b.WriteString(style.Vertical)
+			b.WriteByte('\n')
+		}
+	}
+	line(style.Bottom)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// category shows the score of c in ps. The yatzy bonuses have a row of
+// their own, so yatzy shows only its 50 points.
+func (style Style) category(ps scoring.Scorecard, c scoring.Category) string {
+	score := ps.ScoresByCategory[c]
+	switch {
+	case ps.CatMask&(1<<c) == 0:
+		return style.Unfilled
+	case score == 0:
+		return style.Scratched
+	case c == scoring.CAT_YATZY:
+		score -= ps.YatzyBonusSum()
+	}
+	return fmt.Sprint(score)
+}
@@ -0,0 +1,94 @@
+package render
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AustinJGreen/goyatzy/scoring"
+	"github.com/google/go-cmp/cmp"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// card returns a scorecard with the given categories filled.
+func card(scores map[scoring.Category]uint16) scoring.Scorecard {
+	var ps scoring.Scorecard
+	for c, score := range scores {
+		ps.ScoresByCategory[c] = score
+		ps.CatMask |= 1 << c
+	}
+	return ps
+}
+
+// TestScorecardsGolden compares the tables drawn in each style with
+// testdata/scorecards.<style>.golden. Run with -update to rewrite them
+// after an intended change.
+func TestScorecardsGolden(t *testing.T) {
+	names := []string{"alice", "bob the bot", "carol"}
+	cards := []scoring.Scorecard{
+		// Midway, with sixes scratched and a yatzy bonus.
+		card(map[scoring.Category]uint16{
+			scoring.CAT_ONES:   3,
+			scoring.CAT_SIXES:  0,
+			scoring.CAT_CHANCE: 22,
+			scoring.CAT_YATZY:  150,
+		}),
+		// Not started.
+		{},
+		// Finished with the upper section bonus.
+		card(map[scoring.Category]uint16{
+			scoring.CAT_ONES:            3,
+			scoring.CAT_TWOS:            6,
+			scoring.CAT_THREES:          9,
+			scoring.CAT_FOURS:           12,
+			scoring.CAT_FIVES:           15,
+			scoring.CAT_SIXES:           24,
+			scoring.CAT_THREE_OF_A_KIND: 17,
+			scoring.CAT_FOUR_OF_A_KIND:  0,
+			scoring.CAT_FULL_HOUSE:      25,
+			scoring.CAT_SMALL_STRAIGHT:  30,
+			scoring.CAT_LARGE_STRAIGHT:  40,
+			scoring.CAT_CHANCE:          21,
+			scoring.CAT_YATZY:           0,
+		}),
+	}
+
+	for _, tt := range []struct {
+		name  string
+		style Style
+	}{
+		{"ascii", ASCII},
+		{"unicode", Unicode},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Scorecards(tt.style, names, cards) + "\n"
+			path := filepath.Join("testdata", "scorecards."+tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("reading golden file (run with -update to create it): %v", err)
+			}
+			if diff := cmp.Diff(strings.Split(got, "\n"), strings.Split(string(want), "\n")); diff != "" {
+				t.Errorf("Scorecards() does not match %s (-got, +want):\n%s", path, diff)
+			}
+		})
+	}
+}
+
+// TestScorecardsMissingNames checks that cards past the end of the names
+// are headed "player N".
+func TestScorecardsMissingNames(t *testing.T) {
+	got := strings.SplitN(Scorecards(ASCII, []string{"alice"}, make([]scoring.Scorecard, 2)), "\n", 3)[1]
+	for _, want := range []string{"alice", "player 2"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Scorecards() header %q does not contain %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package scoring
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+)
+
+// bonusState is the part of a scorecard that decides whether the upper
+// section bonus can still be earned.
+type bonusState struct {
+	upperOpen uint8 // mask of open upper categories, ones in the lowest bit
+	lowerOpen uint8 // number of open lower categories
+	upperSum  uint8 // upper section total, capped at UpperSectionMinBonusSum
+}
+
+func newBonusState(ps Scorecard) bonusState {
+	var s bonusState
+	var sum uint16
+	for c := CAT_ONES; c <= CAT_SIXES; c++ {
+		if ps.CatMask&(1<<c) == 0 {
+			s.upperOpen |= 1 << c
+		} else {
+			sum += ps.ScoresByCategory[c]
+		}
+	}
+	for c := CAT_THREE_OF_A_KIND; c <= CAT_YATZY; c++ {
+		if ps.CatMask&(1<<c) == 0 {
+			s.lowerOpen++
+		}
+	}
+	s.upperSum = uint8(min(sum, UpperSectionMinBonusSum))
+	return s
+}
+
+// fill returns the state after filling upper category c with roll r.
+func (s bonusState) fill(c Category, r dice.Counts) bonusState {
+	d := dice.Die(c-CAT_ONES) + dice.DIE_ONE
+	s.upperOpen &^= 1 << c
+	s.upperSum = uint8(min(int(s.upperSum)+r.Count(d)*int(d), UpperSectionMinBonusSum))
+	return s
+}
+
+// bonusTable is the probability of earning the bonus from the start of a
+// turn in every state a scorecard can reach from an empty one. It does
+// not depend on the scorecard a state came from, so it is computed once,
+// on first use, and shared by all of them. It is only read after, so it
+// needs no lock.
+var bonusTable = sync.OnceValue(func() map[bonusState]float64 {
+	b := &bonusSolver{memo: make(map[bonusState]float64)}
+	b.probability(newBonusState(Scorecard{}))
+	return b.memo
+})
+
+// bonusSolver computes the probability of earning the bonus, looking up
+// states in known and remembering any others it solves in memo. Only
+// scorecards that cannot be reached in a game, such as ones with more
+// points in a category than its dice can score, need memo.
+type bonusSolver struct {
+	known map[bonusState]float64
+	memo  map[bonusState]float64
+}
+
+func newBonusSolver() *bonusSolver {
+	return &bonusSolver{known: bonusTable()}
+}
+
+// BonusProbability returns the exact probability that ps earns the upper
+// section bonus from the start of a turn, playing every remaining turn
+// to earn it (any open lower category is somewhere to put a bad roll).
+func BonusProbability(ps Scorecard) float64 {
+	return newBonusSolver().probability(newBonusState(ps))
+}
+
+// TurnBonusProbability returns the exact probability that ps earns the
+// upper section bonus with roll r on roll rollCnt of the current turn,
+// playing for it. Before the first roll, r is ignored and the probability
+// is that of BonusProbability.
+func TurnBonusProbability(ps Scorecard, r dice.Roll, rollCnt int) float64 {
+	s := newBonusState(ps)
+	if p, ok := s.settled(); ok {
+		return p
+	}
+	if rollCnt <= 0 {
+		return newBonusSolver().probability(s)
+	}
+	stages := newBonusSolver().stages(s)
+	return stages[MaxReRolls-min(rollCnt, MaxReRolls)][r.Counts().Key()]
+}
+
+// HoldBonusProbability returns the exact probability that ps earns the
+// upper section bonus after rerolling the dice not in hold on roll rollCnt
+// of the current turn, playing for it. Before the first roll, hold is
+// ignored and every die is rolled. With no rerolls left, the rerolled
+// dice are final.
+func HoldBonusProbability(ps Scorecard, hold dice.Counts, rollCnt int) float64 {
+	s := newBonusState(ps)
+	if p, ok := s.settled(); ok {
+		return p
+	}
+	if rollCnt <= 0 {
+		return newBonusSolver().probability(s)
+	}
+	stages := newBonusSolver().stages(s)
+	return dice.ExpectedValue(&stages[max(MaxReRolls-rollCnt-1, 0)], hold)
+}
+
+// settled returns the probability of earning the bonus if it no longer
+// depends on the dice.
+func (s bonusState) settled() (float64, bool) {
+	if s.upperSum >= UpperSectionMinBonusSum {
+		return 1, true
+	}
+	maxLeft := 0
+	for c := CAT_ONES; c <= CAT_SIXES; c++ {
+		if s.upperOpen&(1<<c) != 0 {
+			maxLeft += 5 * (int(c-CAT_ONES) + 1)
+		}
+	}
+	if int(s.upperSum)+maxLeft < UpperSectionMinBonusSum {
+		return 0, true
+	}
+	return 0, false
+}
+
+// probability returns the probability of earning the bonus from the start
+// of a turn in s.
+func (b *bonusSolver) probability(s bonusState) float64 {
+	if p, ok := s.settled(); ok {
+		return p
+	}
+	if p, ok := b.known[s]; ok {
+		return p
+	}
+	if p, ok := b.memo[s]; ok {
+		return p
+	}
+	stages := b.stages(s)
+	var p float64
+	for _, o := range dice.RerollDistribution(0) {
+		p += o.P * stages[MaxReRolls-1][o.Key]
+	}
+	if b.memo == nil {
+		b.memo = make(map[bonusState]float64)
+	}
+	b.memo[s] = p
+	return p
+}
+
+// stages returns the probability of earning the bonus in s for every roll
+// with each number of rerolls left this turn, indexed by dice.Roll.Key.
+func (b *bonusSolver) stages(s bonusState) *[MaxReRolls][dice.DistinctRolls]float64 {
+	// Filling an upper category only depends on how many of its dice
+	// were rolled.
+	var dump float64
+	if s.lowerOpen > 0 {
+		next := s
+		next.lowerOpen--
+		dump = b.probability(next)
+	}
+	var fills [CAT_SIXES + 1][6]float64
+	for c := CAT_ONES; c <= CAT_SIXES; c++ {
+		if s.upperOpen&(1<<c) == 0 {
+			continue
+		}
+		d := dice.Die(c-CAT_ONES) + dice.DIE_ONE
+		for n := range 6 {
+			var r dice.Counts
+			for range n {
+				r = r.Add(d)
+			}
+			fills[c][n] = b.probability(s.fill(Category(c), r))
+		}
+	}
+
+	stages := new([MaxReRolls][dice.DistinctRolls]float64)
+	for key := range stages[0] {
+		r := dice.Multiset(key)
+		best := dump
+		for c := CAT_ONES; c <= CAT_SIXES; c++ {
+			if s.upperOpen&(1<<c) != 0 {
+				best = max(best, fills[c][r.Count(dice.Die(c-CAT_ONES)+dice.DIE_ONE)])
+			}
+		}
+		stages[0][key] = best
+	}
+	dice.MaxStages(stages[:], true)
+	return stages
+}
+
+// UpperSum returns the total of the upper section.
+func (ps Scorecard) UpperSum() uint16 {
+	var sum uint16
+	for c := CAT_ONES; c <= CAT_SIXES; c++ {
+		sum += ps.ScoresByCategory[c]
+	}
+	return sum
+}
+
+// LowerSum returns the total of the lower section, including any yatzy
+// bonuses.
+func (ps Scorecard) LowerSum() uint16 {
+	var sum uint16
+	for c := CAT_THREE_OF_A_KIND; c < Categories; c++ {
+		sum += ps.ScoresByCategory[c]
+	}
+	return sum
+}
+
+// YatzyBonusSum returns the points earned for yatzies rolled after yatzy
+// was scored, which are kept in its score.
+func (ps Scorecard) YatzyBonusSum() uint16 {
+	if yatzy := ps.ScoresByCategory[CAT_YATZY]; yatzy > YatzyBonus {
+		return yatzy - yatzy%YatzyBonus
+	}
+	return 0
+}
+
+// BonusString returns the upper section bonus if it is decided, or the
+// probability of earning it.
+func (ps Scorecard) BonusString() string {
+	if ps.UpperSum() >= UpperSectionMinBonusSum {
+		return strconv.Itoa(UpperSectionBonus)
+	}
+	p := BonusProbability(ps)
+	if p == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%.1f%%", 100*p)
+}
@@ -0,0 +1,128 @@
+package scoring
+
+import (
+	"math"
+	"testing"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+)
+
+// upperCard returns a scorecard with every category filled except open,
+// and upper section total sum.
+func upperCard(sum uint16, open ...Category) Scorecard {
+	ps := Scorecard{CatMask: AllFilled}
+	ps.ScoresByCategory[CAT_ONES] = sum
+	for _, c := range open {
+		ps.CatMask &^= 1 << c
+	}
+	return ps
+}
+
+func TestBonusProbability(t *testing.T) {
+	// The chance of a die showing a six within three rolls when kept.
+	six := 1 - 125.0/216
+	for _, tt := range []struct {
+		name string
+		ps   Scorecard
+		want float64
+	}{
+		{
+			name: "already earned",
+			ps:   upperCard(63, CAT_SIXES),
+			want: 1,
+		},
+		{
+			name: "out of reach",
+			ps:   upperCard(33, CAT_FIVES),
+			want: 0,
+		},
+		{
+			name: "five sixes",
+			ps:   upperCard(33, CAT_SIXES),
+			want: math.Pow(six, 5),
+		},
+		{
+			name: "four sixes",
+			ps:   upperCard(39, CAT_SIXES),
+			want: 5*math.Pow(six, 4)*(1-six) + math.Pow(six, 5),
+		},
+		{
+			name: "five sixes with a turn to spare",
+			ps:   upperCard(33, CAT_SIXES, CAT_CHANCE),
+			want: 1 - math.Pow(1-math.Pow(six, 5), 2),
+		},
+		{
+			name: "upper section filled",
+			ps:   upperCard(62, CAT_CHANCE),
+			want: 0,
+		},
+	} {
+		if got := BonusProbability(tt.ps); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%s: BonusProbability() = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTurnBonusProbability(t *testing.T) {
+	six := 1 - 25.0/36
+	ps := upperCard(39, CAT_SIXES)
+	for _, tt := range []struct {
+		r       dice.Roll
+		rollCnt int
+		want    float64
+	}{
+		{dice.RollOf([5]dice.Die{dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX, dice.DIE_ONE}), 1, 1},
+		{dice.RollOf([5]dice.Die{dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX, dice.DIE_ONE, dice.DIE_ONE}), 3, 0},
+		{dice.RollOf([5]dice.Die{dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX, dice.DIE_ONE, dice.DIE_ONE}), 2, 1 - 25.0/36},
+		{dice.RollOf([5]dice.Die{dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX, dice.DIE_ONE, dice.DIE_ONE}), 1, 1 - (1-six)*(1-six)},
+		{0, 0, BonusProbability(ps)},
+	} {
+		if got := TurnBonusProbability(ps, tt.r, tt.rollCnt); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("TurnBonusProbability(%s, %d) = %v; want %v", tt.r, tt.rollCnt, got, tt.want)
+		}
+	}
+}
+
+func TestHoldBonusProbability(t *testing.T) {
+	six := 1.0 / 6
+	ps := upperCard(39, CAT_SIXES)
+	for _, tt := range []struct {
+		hold    dice.Counts
+		rollCnt int
+		want    float64
+	}{
+		{0, 0, BonusProbability(ps)},
+		{dice.Count(dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX), 1, 1},
+		{dice.Count(dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX), 2, 1 - (1-six)*(1-six)},
+		{dice.Count(dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX), MaxReRolls, 1 - (1-six)*(1-six)},
+	} {
+		if got := HoldBonusProbability(ps, tt.hold, tt.rollCnt); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("HoldBonusProbability(%s, %d) = %v; want %v", tt.hold, tt.rollCnt, got, tt.want)
+		}
+	}
+}
+
+func TestSectionSums(t *testing.T) {
+	for _, tt := range []struct {
+		name                   string
+		yatzy                  uint16
+		wantLower, wantBonuses uint16
+	}{
+		{name: "scratched", yatzy: 0, wantLower: 20},
+		{name: "scored", yatzy: 50, wantLower: 70},
+		{name: "two bonuses", yatzy: 250, wantLower: 270, wantBonuses: 200},
+	} {
+		ps := upperCard(12)
+		ps.ScoresByCategory[CAT_CHANCE] = 20
+		ps.ScoresByCategory[CAT_YATZY] = tt.yatzy
+		if got := ps.LowerSum(); got != tt.wantLower {
+			t.Errorf("%s: LowerSum() = %d; want %d", tt.name, got, tt.wantLower)
+		}
+		if got := ps.YatzyBonusSum(); got != tt.wantBonuses {
+			t.Errorf("%s: YatzyBonusSum() = %d; want %d", tt.name, got, tt.wantBonuses)
+		}
+		if got := ps.UpperSum(); got != 12 {
+			t.Errorf("%s: UpperSum() = %d; want 12", tt.name, got)
+		}
+	}
+}
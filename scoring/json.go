@@ -0,0 +1,78 @@
+package scoring
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+)
+
+func (c Category) MarshalText() ([]byte, error) {
+	if c >= Categories {
+		return nil, fmt.Errorf("unknown category %d", c)
+	}
+	return []byte(c.String()), nil
+}
+
+func (c *Category) UnmarshalText(text []byte) error {
+	for i := range Category(Categories) {
+		if i.String() == string(text) {
+			*c = i
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unknown category %q", dice.ErrInvalidJSON, text)
+}
+
+// ValidCategoryScore reports whether score can be the final score of c.
+func ValidCategoryScore(c Category, score uint16) bool {
+	switch c {
+	case CAT_ONES, CAT_TWOS, CAT_THREES, CAT_FOURS, CAT_FIVES, CAT_SIXES:
+		face := uint16(1 + (c - CAT_ONES))
+		return score%face == 0 && score <= 5*face
+	case CAT_THREE_OF_A_KIND, CAT_FOUR_OF_A_KIND:
+		return score == 0 || (score >= 5 && score <= 30)
+	case CAT_CHANCE:
+		return score >= 5 && score <= 30
+	case CAT_FULL_HOUSE:
+		return score == 0 || score == 25
+	case CAT_SMALL_STRAIGHT:
+		return score == 0 || score == 30
+	case CAT_LARGE_STRAIGHT:
+		return score == 0 || score == 40
+	case CAT_YATZY:
+		return score == 0 || (score >= 50 && (score-50)%YatzyBonus == 0 && score <= 50+(Categories-1)*YatzyBonus)
+	}
+	return false
+}
+
+type scorecardJSON struct {
+	Scores map[Category]uint16 `json:"scores"`
+}
+
+func (ps Scorecard) MarshalJSON() ([]byte, error) {
+	sj := scorecardJSON{Scores: make(map[Category]uint16)}
+	for c := range Category(Categories) {
+		if ps.CatMask&(1<<c) != 0 {
+			sj.Scores[c] = ps.ScoresByCategory[c]
+		}
+	}
+	return json.Marshal(sj)
+}
+
+func (ps *Scorecard) UnmarshalJSON(data []byte) error {
+	var sj scorecardJSON
+	if err := json.Unmarshal(data, &sj); err != nil {
+		return err
+	}
+	var next Scorecard
+	for c, score := range sj.Scores {
+		if !ValidCategoryScore(c, score) {
+			return fmt.Errorf("%w: %d is not a possible %s score", dice.ErrInvalidJSON, score, c)
+		}
+		next.ScoresByCategory[c] = score
+		next.CatMask |= 1 << c
+	}
+	*ps = next
+	return nil
+}
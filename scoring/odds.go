@@ -0,0 +1,159 @@
+package scoring
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+)
+
+// ErrInvalidRollsLeft is returned for asking the odds with a number of
+// rolls left that no turn can have.
+var ErrInvalidRollsLeft = errors.New("invalid number of rolls left")
+
+// maxRollScore is the highest score of any category for a single roll,
+// without bonuses.
+const maxRollScore = 50
+
+// scoreDist is a probability distribution over the score of a category,
+// indexed by score.
+type scoreDist [maxRollScore + 1]float64
+
+// hit returns the probability of scoring more than 0.
+func (d *scoreDist) hit() float64 {
+	return 1 - d[0]
+}
+
+func (d *scoreDist) mean() float64 {
+	var m float64
+	for score, p := range d {
+		m += float64(score) * p
+	}
+	return m
+}
+
+// better reports whether d is more likely to score than o, or as likely
+// and higher scoring on average.
+func (d *scoreDist) better(o *scoreDist) bool {
+	const eps = 1e-12
+	if hd, ho := d.hit(), o.hit(); hd > ho+eps || hd < ho-eps {
+		return hd > ho
+	}
+	return d.mean() > o.mean()+eps
+}
+
+// ScoreProbability is the probability of a score.
+type ScoreProbability struct {
+	Score uint16
+	P     float64
+}
+
+// CategoryOdds is the chance of scoring in a category.
+type CategoryOdds struct {
+	Cat Category
+	// P is the probability of scoring more than 0.
+	P float64
+	// Scores is the probability of every possible score, lowest first.
+	Scores []ScoreProbability
+}
+
+func (co CategoryOdds) Mean() float64 {
+	var m float64
+	for _, sp := range co.Scores {
+		m += float64(sp.Score) * sp.P
+	}
+	return m
+}
+
+// RollOdds returns the exact odds of scoring in each category when
+// rerolling the dice in r not in hold, with rollsLeft rolls left in the
+// turn (including this reroll). If rollsLeft is 0, r is final and hold is
+// ignored. Each category is played for on its own: the remaining rerolls
+// keep whatever dice make scoring in it most likely, and then score most
+// on average.
+func RollOdds(r dice.Roll, hold dice.Counts, rollsLeft int) ([Categories]CategoryOdds, error) {
+	var odds [Categories]CategoryOdds
+	if rollsLeft < 0 || rollsLeft >= MaxReRolls {
+		return odds, fmt.Errorf("%w: %d rolls left must be between 0 and %d", ErrInvalidRollsLeft, rollsLeft, MaxReRolls-1)
+	}
+	for _, d := range r.Dice() {
+		if d < dice.DIE_ONE || d > dice.DIE_SIX {
+			return odds, fmt.Errorf("%w: die value %d", dice.ErrInvalidHold, d)
+		}
+	}
+	// Rerolling every die is not a move, but its odds are still asked for,
+	// such as to compare holds against.
+	if rollsLeft > 0 && hold != 0 {
+		if err := dice.ValidateHold(r, hold); err != nil {
+			return odds, err
+		}
+	}
+
+	for c := range Category(Categories) {
+		var dist scoreDist
+		if rollsLeft == 0 {
+			dist[RollScore(r, c)] = 1
+		} else {
+			best := bestScoreDists(c, rollsLeft-1)
+			mixOutcomes(&dist, best, hold)
+		}
+		odds[c] = CategoryOdds{Cat: c, P: dist.hit()}
+		for score, p := range dist {
+			if p > 0 {
+				odds[c].Scores = append(odds[c].Scores, ScoreProbability{uint16(score), p})
+			}
+		}
+	}
+	return odds, nil
+}
+
+// bestScoreDists returns the distribution of the score of c for every
+// roll with k rerolls left, playing to score in c. It is indexed by
+// dice.Roll.Key.
+func bestScoreDists(c Category, k int) *[dice.DistinctRolls]scoreDist {
+	stages := make([][dice.DistinctRolls]scoreDist, k+1)
+	for key := range stages[0] {
+		stages[0][key][rollScores[key][c]] = 1
+	}
+	dice.Stages(stages, true,
+		func(dists *[dice.DistinctRolls]scoreDist, hold dice.Counts) scoreDist {
+			var d scoreDist
+			mixOutcomes(&d, dists, hold)
+			return d
+		},
+		(*scoreDist).better)
+	return &stages[k]
+}
+
+// mixOutcomes sets d to the distribution from rerolling the dice not in
+// hold, given the distribution for each resulting roll.
+func mixOutcomes(d *scoreDist, dists *[dice.DistinctRolls]scoreDist, hold dice.Counts) {
+	*d = scoreDist{}
+	for _, o := range dice.RerollDistribution(hold) {
+		od := &dists[o.Key]
+		for score, p := range od {
+			d[score] += o.P * p
+		}
+	}
+}
+
+// CategoryMask is a set of categories, with category c in bit c.
+type CategoryMask uint16
+
+// ExpectedBestScore returns the exact expected score of the best category
+// in open (ignoring bonuses) when rerolling the dice not in hold and then
+// playing to maximize it with k more rerolls left. hold must have at most
+// five dice, each one to six; holding none rerolls them all.
+func ExpectedBestScore(open CategoryMask, hold dice.Counts, k int) float64 {
+	// stages[k][r] is the expected score of roll r with k rerolls left.
+	stages := make([][dice.DistinctRolls]float64, k+1)
+	for key := range stages[0] {
+		for c := range Category(Categories) {
+			if open&(1<<c) != 0 {
+				stages[0][key] = max(stages[0][key], float64(rollScores[key][c]))
+			}
+		}
+	}
+	dice.MaxStages(stages, true)
+	return dice.ExpectedValue(&stages[k], hold)
+}
@@ -0,0 +1,230 @@
+package scoring
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestRollOdds(t *testing.T) {
+	opts := []cmp.Option{
+		cmp.AllowUnexported(ScoreProbability{}),
+		cmpopts.EquateApprox(0, 1e-12),
+	}
+	straight := dice.RollOf([5]dice.Die{dice.DIE_TWO, dice.DIE_THREE, dice.DIE_FOUR, dice.DIE_FIVE, dice.DIE_FIVE})
+	for _, tt := range []struct {
+		name      string
+		r         dice.Roll
+		hold      dice.Counts
+		rollsLeft int
+		c         Category
+		want      []ScoreProbability
+	}{
+		{
+			name:      "large straight with two rolls left",
+			r:         straight,
+			hold:      dice.Count(dice.DIE_TWO, dice.DIE_THREE, dice.DIE_FOUR, dice.DIE_FIVE),
+			rollsLeft: 2,
+			c:         CAT_LARGE_STRAIGHT,
+			want:      []ScoreProbability{{0, 4.0 / 9}, {40, 5.0 / 9}},
+		},
+		{
+			name:      "large straight with one roll left",
+			r:         straight,
+			hold:      dice.Count(dice.DIE_TWO, dice.DIE_THREE, dice.DIE_FOUR, dice.DIE_FIVE),
+			rollsLeft: 1,
+			c:         CAT_LARGE_STRAIGHT,
+			want:      []ScoreProbability{{0, 2.0 / 3}, {40, 1.0 / 3}},
+		},
+		{
+			name:      "small straight already made",
+			r:         straight,
+			hold:      dice.Count(dice.DIE_TWO, dice.DIE_THREE, dice.DIE_FOUR, dice.DIE_FIVE),
+			rollsLeft: 1,
+			c:         CAT_SMALL_STRAIGHT,
+			want:      []ScoreProbability{{30, 1}},
+		},
+		{
+			name:      "yatzy holding four of a kind",
+			r:         dice.RollOf([5]dice.Die{dice.DIE_SIX, dice.DIE_SIX, dice.DIE_ONE, dice.DIE_SIX, dice.DIE_SIX}),
+			hold:      dice.Count(dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX),
+			rollsLeft: 2,
+			c:         CAT_YATZY,
+			want:      []ScoreProbability{{0, 25.0 / 36}, {50, 11.0 / 36}},
+		},
+		{
+			name:      "fives rerolling one die",
+			r:         dice.RollOf([5]dice.Die{dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_ONE}),
+			hold:      dice.Count(dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_FIVE),
+			rollsLeft: 1,
+			c:         CAT_FIVES,
+			want:      []ScoreProbability{{20, 5.0 / 6}, {25, 1.0 / 6}},
+		},
+		{
+			name:      "no rolls left",
+			r:         straight,
+			hold:      dice.Count(dice.DIE_TWO),
+			rollsLeft: 0,
+			c:         CAT_CHANCE,
+			want:      []ScoreProbability{{19, 1}},
+		},
+	} {
+		odds, err := RollOdds(tt.r, tt.hold, tt.rollsLeft)
+		if err != nil {
+			t.Errorf("%s: RollOdds() returned unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got := odds[tt.c].Cat; got != tt.c {
+			t.Errorf("%s: odds[%d].cat = %s", tt.name, tt.c, got)
+		}
+		if diff := cmp.Diff(odds[tt.c].Scores, tt.want, opts...); diff != "" {
+			t.Errorf("%s: %s scores do not match (-got, +want):\n%s", tt.name, tt.c, diff)
+		}
+		for _, co := range odds {
+			var sum float64
+			for _, sp := range co.Scores {
+				sum += sp.P
+			}
+			if math.Abs(sum-1) > 1e-9 {
+				t.Errorf("%s: %s probabilities sum to %v; want 1", tt.name, co.Cat, sum)
+			}
+		}
+	}
+}
+
+// TestRollOddsYatzy checks the odds of a yatzy, the only category that
+// scores a single value, against ExpectedBestScore.
+func TestRollOddsYatzy(t *testing.T) {
+	r := dice.RollOf([5]dice.Die{dice.DIE_ONE, dice.DIE_ONE, dice.DIE_THREE, dice.DIE_FOUR, dice.DIE_SIX})
+	for key := dice.DistinctRolls; key < dice.DistinctHolds; key++ {
+		hold := dice.Multiset(key)
+		if dice.ValidateHold(r, hold) != nil {
+			continue
+		}
+		for rollsLeft := 1; rollsLeft < MaxReRolls; rollsLeft++ {
+			odds, err := RollOdds(r, hold, rollsLeft)
+			if err != nil {
+				t.Fatalf("RollOdds() returned unexpected error: %v", err)
+			}
+			want := ExpectedBestScore(1<<CAT_YATZY, hold, rollsLeft-1) / 50
+			if got := odds[CAT_YATZY].P; math.Abs(got-want) > 1e-12 {
+				t.Errorf("odds of yatzy holding %s with %d rolls left = %v; want %v", hold, rollsLeft, got, want)
+			}
+		}
+	}
+}
+
+func TestRollOddsErrors(t *testing.T) {
+	r := dice.RollOf([5]dice.Die{dice.DIE_TWO, dice.DIE_THREE, dice.DIE_FOUR, dice.DIE_FIVE, dice.DIE_FIVE})
+	for _, tt := range []struct {
+		name      string
+		r         dice.Roll
+		hold      dice.Counts
+		rollsLeft int
+		want      error
+	}{
+		{"too many rolls", r, 0, MaxReRolls, ErrInvalidRollsLeft},
+		{"negative rolls", r, 0, -1, ErrInvalidRollsLeft},
+		{"hold not rolled", r, dice.Count(dice.DIE_SIX), 1, dice.ErrHoldNotInRoll},
+		{"hold everything", r, r.Counts(), 1, dice.ErrInvalidHold},
+		{"unset dice", 0, 0, 0, dice.ErrInvalidHold},
+	} {
+		_, err := RollOdds(tt.r, tt.hold, tt.rollsLeft)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: RollOdds() = %v; want %v", tt.name, err, tt.want)
+		}
+		if errors.Is(err, ErrNoRerollsLeft) {
+			t.Errorf("%s: RollOdds() = %v; want an error other than %v", tt.name, err, ErrNoRerollsLeft)
+		}
+	}
+}
+
+func TestExpectedBestScore(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		open CategoryMask
+		hold dice.Counts
+		k    int
+		want float64
+	}{
+		{
+			name: "yatzy holding four sixes",
+			open: 1 << CAT_YATZY,
+			hold: dice.Count(dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX),
+			want: 50.0 / 6,
+		},
+		{
+			name: "yatzy holding four sixes with a reroll",
+			open: 1 << CAT_YATZY,
+			hold: dice.Count(dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX),
+			k:    1,
+			want: 50 * (1 - 25.0/36),
+		},
+		{
+			name: "sixes holding nothing",
+			open: 1 << CAT_SIXES,
+			want: 5,
+		},
+		{
+			name: "sixes over a turn",
+			open: 1 << CAT_SIXES,
+			k:    2,
+			want: 6 * 5 * (1 - 125.0/216),
+		},
+		{
+			// Known chance of a yatzy in a turn, playing for it.
+			name: "yatzy over a turn",
+			open: 1 << CAT_YATZY,
+			k:    2,
+			want: 50 * 2783176.0 / 60466176,
+		},
+		{
+			name: "nothing open",
+			k:    2,
+		},
+		{
+			name: "chance holding five sixes",
+			open: 1 << CAT_CHANCE,
+			hold: dice.Count(dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX),
+			k:    2,
+			want: 30,
+		},
+	} {
+		if got := ExpectedBestScore(tt.open, tt.hold, tt.k); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: ExpectedBestScore(%#x, %s, %d) = %v; want %v", tt.name, tt.open, tt.hold, tt.k, got, tt.want)
+		}
+	}
+}
+
+// TestExpectedBestScoreNoRerolls checks the expectation with no rerolls
+// left against summing over every ordered reroll.
+func TestExpectedBestScoreNoRerolls(t *testing.T) {
+	open := CategoryMask(1<<CAT_FULL_HOUSE | 1<<CAT_SMALL_STRAIGHT | 1<<CAT_THREES)
+	for key := dice.DistinctRolls; key < dice.DistinctHolds; key++ {
+		hold := dice.Multiset(key)
+		var sum, n float64
+		for _, r := range dice.Rolls() {
+			// Take the first 5-len(hold) dice of every ordered roll as
+			// the rerolled dice; each reroll appears equally often.
+			dc, dice := hold, r.Dice()
+			for _, d := range dice[:5-hold.Len()] {
+				dc = dc.Add(d)
+			}
+			var best uint16
+			for c := range Category(Categories) {
+				if open&(1<<c) != 0 {
+					best = max(best, RollScore(dc.Roll(), c))
+				}
+			}
+			sum += float64(best)
+			n++
+		}
+		if got, want := ExpectedBestScore(open, hold, 0), sum/n; math.Abs(got-want) > 1e-9 {
+			t.Errorf("ExpectedBestScore(%#x, %s, 0) = %v; want %v", open, hold, got, want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package scoring
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/google/go-cmp/cmp"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// referenceScore is a deliberately naive score of roll r in category c,
+// written from the rules without sharing any code with getScoreData.
+func referenceScore(r [5]dice.Die, c Category) uint16 {
+	var counts [7]int
+	sum := 0
+	for _, d := range r {
+		counts[d]++
+		sum += int(d)
+	}
+	hasRun := func(n int) bool {
+		for start := 1; start+n-1 <= 6; start++ {
+			run := true
+			for v := start; v < start+n; v++ {
+				if counts[v] == 0 {
+					run = false
+				}
+			}
+			if run {
+				return true
+			}
+		}
+		return false
+	}
+	hasCount := func(n int) bool {
+		for v := 1; v <= 6; v++ {
+			if counts[v] == n {
+				return true
+			}
+		}
+		return false
+	}
+	maxCount := 0
+	for v := 1; v <= 6; v++ {
+		maxCount = max(maxCount, counts[v])
+	}
+
+	switch c {
+	case CAT_ONES, CAT_TWOS, CAT_THREES, CAT_FOURS, CAT_FIVES, CAT_SIXES:
+		v := int(c-CAT_ONES) + 1
+		return uint16(v * counts[v])
+	case CAT_THREE_OF_A_KIND:
+		if maxCount >= 3 {
+			return uint16(sum)
+		}
+	case CAT_FOUR_OF_A_KIND:
+		if maxCount >= 4 {
+			return uint16(sum)
+		}
+	case CAT_FULL_HOUSE:
+		if hasCount(3) && hasCount(2) {
+			return 25
+		}
+	case CAT_SMALL_STRAIGHT:
+		if hasRun(4) {
+			return 30
+		}
+	case CAT_LARGE_STRAIGHT:
+		if hasRun(5) {
+			return 40
+		}
+	case CAT_CHANCE:
+		return uint16(sum)
+	case CAT_YATZY:
+		if maxCount == 5 {
+			return 50
+		}
+	}
+	return 0
+}
+
+func TestScoresAgainstReference(t *testing.T) {
+	for _, r2 := range dice.Rolls() {
+		for c := range Category(Categories) {
+			want := referenceScore(r2.Dice(), c)
+			if got := RollScore(r2, c); got != want {
+				t.Errorf("RollScore(%s, %s) = %d; want %d", r2, c, got, want)
+			}
+			if got := getScoreData(r2, c).score; got != want {
+				t.Errorf("getScoreData(%s, %s).score = %d; want %d", r2, c, got, want)
+			}
+		}
+	}
+}
+
+// TestScoresGolden compares the score of every distinct roll with
+// testdata/scores.golden. There is one golden file per rule set; this
+// version of the game only has the one. Run with -update to rewrite it
+// after an intended change to scoring.
+func TestScoresGolden(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("roll")
+	for _, code := range catCodes {
+		fmt.Fprintf(&b, " %s", code)
+	}
+	b.WriteString("\n")
+	for _, r2 := range dice.SortedRolls() {
+		for _, d := range r2.Dice() {
+			fmt.Fprintf(&b, "%d", d)
+		}
+		for c := range Category(Categories) {
+			fmt.Fprintf(&b, " %d", RollScore(r2, c))
+		}
+		b.WriteString("\n")
+	}
+
+	path := filepath.Join("testdata", "scores.golden")
+	if *update {
+		if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file (run with -update to create it): %v", err)
+	}
+	if diff := cmp.Diff(strings.Split(b.String(), "\n"), strings.Split(string(want), "\n")); diff != "" {
+		t.Errorf("scores do not match %s (-got, +want):\n%s", path, diff)
+	}
+}
@@ -0,0 +1,488 @@
+// Package scoring has the rules of yatzy: the categories, what every roll
+// scores in them, scorecards and the odds of scoring.
+package scoring
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+	"strings"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+)
+
+type scoreData struct {
+	score uint16
+	used  [][]int // dice indices that could be used for the score.
+}
+
+func getScoreData(r2 dice.Roll, c Category) scoreData {
+	r := r2.Dice()
+	used := make([][]int, 1, 2)
+	used[0] = make([]int, 0, 5)
+	switch c {
+	case CAT_ONES, CAT_TWOS, CAT_THREES, CAT_FOURS, CAT_FIVES, CAT_SIXES:
+		var score uint16
+		catScoreVal := uint16(1 + (c - CAT_ONES))
+		for d := 0; d < 5; d++ {
+			if dieCat(r[d]) == c {
+				score += catScoreVal
+				used[0] = append(used[0], d)
+			}
+		}
+		if score == 0 {
+			return scoreData{}
+		}
+		return scoreData{
+			score: score,
+			used:  used,
+		}
+	case CAT_THREE_OF_A_KIND, CAT_FOUR_OF_A_KIND:
+		var freqs [7]byte
+		var maxFreq byte
+		var maxFreqDieIdx int
+		var sum uint16
+		for d := 0; d < 5; d++ {
+			freqs[r[d]]++
+			if freqs[r[d]] > maxFreq {
+				maxFreq = freqs[r[d]]
+				maxFreqDieIdx = d
+			}
+			maxFreq = max(maxFreq, freqs[r[d]])
+			sum += uint16(r[d])
+		}
+		req := byte(3 + (c - CAT_THREE_OF_A_KIND))
+		if maxFreq >= req {
+			for d := range 5 {
+				if r[d] == r[maxFreqDieIdx] {
+					used[0] = append(used[0], d)
+				}
+			}
+			return scoreData{
+				score: sum,
+				used:  used,
+			}
+		}
+		return scoreData{}
+	case CAT_FULL_HOUSE:
+		var freqs [7]byte
+		for d := 0; d < 5; d++ {
+			freqs[r[d]]++
+		}
+		var hasTwo, hasThree bool
+		for _, f := range freqs {
+			if f == 2 {
+				hasTwo = true
+			} else if f == 3 {
+				hasThree = true
+			}
+		}
+		if hasTwo && hasThree {
+			return scoreData{
+				score: 25,
+				used:  [][]int{{0, 1, 2, 3, 4}},
+			}
+		}
+		return scoreData{}
+	case CAT_SMALL_STRAIGHT, CAT_LARGE_STRAIGHT:
+		n, score := dice.Die(4), uint16(30)
+		if c == CAT_LARGE_STRAIGHT {
+			n, score = 5, 40
+		}
+		var indicesByVal [7][]int
+		for idx, d := range r {
+			indicesByVal[d] = append(indicesByVal[d], idx)
+		}
+		var straights [][]int
+		for start := dice.DIE_ONE; start+n-1 <= dice.DIE_SIX; start++ {
+			// Every way of picking one die of each value in the run, so
+			// a duplicated value gives a set for each of its dice.
+			sets := [][]int{nil}
+			for d := start; d < start+n; d++ {
+				var next [][]int
+				for _, set := range sets {
+					for _, idx := range indicesByVal[d] {
+						next = append(next, append(slices.Clip(set), idx))
+					}
+				}
+				sets = next
+			}
+			straights = append(straights, sets...)
+		}
+		if len(straights) == 0 {
+			return scoreData{}
+		}
+		return scoreData{
+			score: score,
+			used:  straights,
+		}
+	case CAT_YATZY:
+		for d := 1; d < 5; d++ {
+			if r[d-1] != r[d] {
+				return scoreData{}
+			}
+		}
+		return scoreData{
+			score: 50,
+			used:  [][]int{{0, 1, 2, 3, 4}},
+		}
+	case CAT_CHANCE:
+		var sum uint16
+		for d := 0; d < 5; d++ {
+			sum += uint16(r[d])
+		}
+		return scoreData{
+			score: sum,
+			used:  [][]int{{0, 1, 2, 3, 4}},
+		}
+	default:
+		panic("unimplemented")
+	}
+}
+
+// rollScores is the score of every category for every distinct roll. It is
+// consulted on every scorecard update, so it must stay a flat array.
+var rollScores [dice.DistinctRolls][Categories]uint16
+
+// maxUsedSets is the most distinct sets of dice that score a category in
+// any roll (e.g. 1,2,3,4,5 has two small straights).
+const maxUsedSets = 2
+
+// usedDice are the sets of dice in an ordered roll that can be used for a
+// score, each as a mask of die indices.
+type usedDice struct {
+	n     uint8
+	masks [maxUsedSets]uint8
+}
+
+// indices returns the die indices of each set in ascending order.
+func (u usedDice) indices() [][]int {
+	var used [][]int
+	for _, mask := range u.masks[:u.n] {
+		var set []int
+		for idx := range 5 {
+			if mask&(1<<idx) != 0 {
+				set = append(set, idx)
+			}
+		}
+		used = append(used, set)
+	}
+	return used
+}
+
+// rollUsed is the dice used for the score of every category for every
+// ordered roll, indexed by dice.Roll.Ordinal.
+var rollUsed [6 * 6 * 6 * 6 * 6][Categories]usedDice
+
+// RollScore returns the score of r in category c, without bonuses.
+func RollScore(r dice.Roll, c Category) uint16 {
+	return rollScores[r.Key()][c]
+}
+
+// subset die -> EV by category
+// ex: [one one one one] -> yatzy would be 1/6 so EV would be (50 * 1/6)
+// ex: [one two three four] -> large straight would be (1/6 * 40)
+// ex: [one two three four] -> yatzy would be 0
+var possibleSubDieByScore [13]map[dice.Counts][]dice.Die
+
+// var expectedValueBySubset map[int][13]float64
+
+func init() {
+	for c := CAT_ONES; c <= CAT_YATZY; c++ {
+		possibleSubDieByScore[c] = make(map[dice.Counts][]dice.Die)
+	}
+
+	for ord, r2 := range dice.Rolls() {
+		key := r2.Key()
+		for c := CAT_ONES; c <= CAT_YATZY; c++ {
+			scoreData := getScoreData(r2, Category(c))
+			rollScores[key][c] = scoreData.score
+			if len(scoreData.used) > maxUsedSets {
+				panic(fmt.Sprintf("%s uses more than %d sets of dice for %s", r2, maxUsedSets, Category(c)))
+			}
+			if scoreData.score > 0 {
+				u := &rollUsed[ord][c]
+				for _, used := range scoreData.used {
+					for _, idx := range used {
+						u.masks[u.n] |= 1 << idx
+					}
+					u.n++
+				}
+			}
+
+			if scoreData.score > 0 {
+				for _, used := range scoreData.used {
+					var usedToScore []dice.Die
+					for _, idx := range used {
+						usedToScore = append(usedToScore, r2.Die(idx))
+					}
+					possibleSubDieByScore[c][dice.Count(usedToScore...)] = usedToScore
+				}
+			}
+		}
+	}
+
+	/*
+		for c, m := range possibleSubDieByScore {
+			for _, d := range m {
+				fmt.Printf("can score %s with %+v\n", category(c), d)
+			}
+		}
+	*/
+}
+
+// Category is a box on the scorecard, from CAT_ONES to CAT_YATZY.
+type Category uint16
+
+const (
+	CAT_ONES = iota
+	CAT_TWOS
+	CAT_THREES
+	CAT_FOURS
+	CAT_FIVES
+	CAT_SIXES
+	CAT_THREE_OF_A_KIND
+	CAT_FOUR_OF_A_KIND
+	CAT_FULL_HOUSE
+	CAT_SMALL_STRAIGHT
+	CAT_LARGE_STRAIGHT
+	CAT_CHANCE
+	CAT_YATZY
+)
+
+func (c Category) String() string {
+	return [13]string{
+		"ones",
+		"twos",
+		"threes",
+		"fours",
+		"fives",
+		"sixes",
+		"three of a kind",
+		"four of a kind",
+		"full house",
+		"small straight",
+		"large straight",
+		"chance",
+		"yatzy",
+	}[c]
+}
+
+func dieCat(d dice.Die) Category {
+	return [...]Category{CAT_ONES, CAT_TWOS, CAT_THREES, CAT_FOURS, CAT_FIVES, CAT_SIXES}[d-dice.DIE_ONE]
+}
+
+// catCodes are the short category codes used in position notation.
+var catCodes = [Categories]string{
+	CAT_ONES:            "1s",
+	CAT_TWOS:            "2s",
+	CAT_THREES:          "3s",
+	CAT_FOURS:           "4s",
+	CAT_FIVES:           "5s",
+	CAT_SIXES:           "6s",
+	CAT_THREE_OF_A_KIND: "3k",
+	CAT_FOUR_OF_A_KIND:  "4k",
+	CAT_FULL_HOUSE:      "fh",
+	CAT_SMALL_STRAIGHT:  "ss",
+	CAT_LARGE_STRAIGHT:  "ls",
+	CAT_CHANCE:          "ch",
+	CAT_YATZY:           "yz",
+}
+
+// Code returns the short code of c used in position notation, such as
+// "fh" for a full house.
+func (c Category) Code() string {
+	return catCodes[c]
+}
+
+func ParseCatCode(code string) (Category, bool) {
+	for c, cc := range catCodes {
+		if cc == code {
+			return Category(c), true
+		}
+	}
+	return 0, false
+}
+
+const AllFilled = 0x1FFF // 13 categories
+
+// Scorecard is one player's scores.
+type Scorecard struct {
+	ScoresByCategory [13]uint16
+	// CatMask is the mask representing which categories
+	// have been filled/used (so can no longer be set).
+	CatMask uint16
+}
+
+// Pretty renders ps as a table.
+func (ps Scorecard) Pretty() string {
+	var bldr strings.Builder
+	bldr.WriteString("┏━━━━━━━━━━━━━━━━━━━┳━━━━━━━━━━━━┓\n")
+	bldr.WriteString(fmt.Sprintf("┃ %-17s ┃ %-10s ┃\n", "Category", "Score"))
+	bldr.WriteString("┣━━━━━━━━━━━━━━━━━━━╋━━━━━━━━━━━━┫\n")
+	for cat, score := range ps.ScoresByCategory {
+		catUsed := ps.CatMask&(1<<cat) != 0
+		var catUsedStr string
+		if catUsed {
+			catUsedStr = "*"
+		}
+		bldr.WriteString(fmt.Sprintf("┃ %-17s ┃ %-10d ┃\n", fmt.Sprintf("%s%s", Category(cat), catUsedStr), score))
+	}
+	bldr.WriteString("┣━━━━━━━━━━━━━━━━━━━╋━━━━━━━━━━━━┫\n")
+	bldr.WriteString(fmt.Sprintf("┃ %-17s ┃ %-10s ┃\n", "upper bonus", ps.BonusString()))
+
+	bldr.WriteString("┗━━━━━━━━━━━━━━━━━━━┻━━━━━━━━━━━━┛")
+	return bldr.String()
+}
+
+// Open returns the categories of ps that have not been filled.
+func (ps Scorecard) Open() CategoryMask {
+	return CategoryMask(^ps.CatMask & AllFilled)
+}
+
+// RawSum returns the total of ps without the upper section bonus.
+func (ps Scorecard) RawSum() uint16 {
+	var sum uint16
+	for _, score := range ps.ScoresByCategory {
+		sum += score
+	}
+	return sum
+}
+
+// Score returns the total of ps, including the upper section bonus.
+func (ps Scorecard) Score() uint16 {
+	total := ps.RawSum()
+	var upperScoreTotal uint16
+	for _, cat := range []Category{
+		CAT_ONES, CAT_TWOS, CAT_THREES, CAT_FOURS, CAT_FIVES, CAT_SIXES,
+	} {
+		upperScoreTotal += ps.ScoresByCategory[cat]
+	}
+	if upperScoreTotal >= 63 {
+		total += 35
+	}
+	return total
+}
+
+// MaxTheoreticalScore returns the highest total ps could still finish with.
+func (ps Scorecard) MaxTheoreticalScore() uint16 {
+	var filledTotal uint16
+	for _, score := range ps.ScoresByCategory {
+		filledTotal += score
+	}
+
+	var upperScoreTotal uint16
+	var maxUpperScoreLeft uint16
+	for _, cat := range []Category{
+		CAT_ONES, CAT_TWOS, CAT_THREES, CAT_FOURS, CAT_FIVES, CAT_SIXES,
+	} {
+		if ps.CatMask&(1<<cat) != 0 {
+			upperScoreTotal += ps.ScoresByCategory[cat]
+		} else {
+			maxUpperScoreLeft += uint16(dice.Die(cat+1) * 5)
+		}
+	}
+
+	if upperScoreTotal+maxUpperScoreLeft >= 63 {
+		filledTotal += 35
+	}
+
+	// For every empty category, assume we score the best possible score.
+	var theoreticalMaxLeft uint16
+	unusedMask := (^ps.CatMask & 0x1FFF)
+	for unusedMask > 0 {
+		cat := Category(bits.TrailingZeros16(unusedMask))
+		switch cat {
+		case CAT_ONES, CAT_TWOS, CAT_THREES, CAT_FOURS, CAT_FIVES, CAT_SIXES:
+			val := 1 + (cat - CAT_ONES)
+			theoreticalMaxLeft += uint16(val * 5)
+		case CAT_THREE_OF_A_KIND, CAT_FOUR_OF_A_KIND, CAT_SMALL_STRAIGHT, CAT_CHANCE:
+			theoreticalMaxLeft += 30
+		case CAT_LARGE_STRAIGHT:
+			theoreticalMaxLeft += 40
+		case CAT_FULL_HOUSE:
+			theoreticalMaxLeft += 25
+		case CAT_YATZY:
+			movesLeft := ps.GetTurnsLeft()
+			theoreticalMaxLeft += uint16(50 + ((movesLeft - 1) * 100))
+		}
+		unusedMask ^= (1 << cat)
+	}
+	if ps.ScoresByCategory[CAT_YATZY] > 0 {
+		// Every turn left can roll another yatzy for a bonus.
+		theoreticalMaxLeft += uint16(ps.GetTurnsLeft() * YatzyBonus)
+	}
+
+	return filledTotal + theoreticalMaxLeft
+}
+
+// GetTurnsLeft returns the number of open categories.
+func (ps Scorecard) GetTurnsLeft() int {
+	return 13 - bits.OnesCount16(ps.CatMask)
+}
+
+const (
+	Categories              = 13
+	UpperSectionMinBonusSum = 63
+	UpperSectionBonus       = 35
+	MaxReRolls              = 3
+	YatzyBonus              = 100
+)
+
+// ErrNoRerollsLeft is returned for rerolling after the dice have been
+// rolled MaxReRolls times in a turn.
+var ErrNoRerollsLeft = errors.New("no rerolls left this turn")
+
+// Update gets the next scorecard calculated after a roll and category are chosen.
+// This function does not check that the category has not been used.
+func (ps Scorecard) Update(r dice.Roll, c Category) Scorecard {
+	var next Scorecard
+	scores := &rollScores[r.Key()]
+	rs := scores[c]
+	next.CatMask = ps.CatMask
+	next.ScoresByCategory = ps.ScoresByCategory
+	next.ScoresByCategory[c] = rs
+	next.CatMask = ps.CatMask | uint16(1<<c)
+
+	if scores[CAT_YATZY] == 0 {
+		return next
+	}
+
+	hasScoredYatzy := ps.ScoresByCategory[CAT_YATZY] > 0
+	if hasScoredYatzy {
+		next.ScoresByCategory[CAT_YATZY] += YatzyBonus
+	}
+
+	// Only allow a joker in the lower section if the respective upper section
+	// category is filled.
+	die := r.Die(0)
+	dieCat := dieCat(die)
+	if ps.CatMask&(1<<dieCat) == 0 {
+		return next // must take points in upper section
+	}
+
+	switch c {
+	// other c's already covered -- add ones that joker helps.
+	case CAT_FULL_HOUSE:
+		next.ScoresByCategory[c] = 25
+	case CAT_SMALL_STRAIGHT:
+		next.ScoresByCategory[c] = 30
+	case CAT_LARGE_STRAIGHT:
+		next.ScoresByCategory[c] = 40
+	}
+	return next
+}
+
+// GetNext checks all available scorecards that would be
+// available for a given roll, appending them to buf. The number of
+// scorecards appended is the number of turns left.
+func (ps Scorecard) GetNext(r dice.Roll, buf []Scorecard) []Scorecard {
+	cur := (^ps.CatMask & 0x1FFF)
+	for cur > 0 {
+		idx := bits.TrailingZeros16(cur)
+		cur ^= (1 << idx)
+		buf = append(buf, ps.Update(r, Category(idx)))
+	}
+	return buf
+}
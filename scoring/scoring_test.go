@@ -0,0 +1,614 @@
+package scoring
+
+import (
+	"math/bits"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/google/go-cmp/cmp"
+)
+
+func DieCatTest(t *testing.T) {
+	for _, tt := range []struct {
+		i dice.Die
+		w Category
+	}{
+		{dice.DIE_ONE, CAT_ONES},
+		{dice.DIE_TWO, CAT_TWOS},
+		{dice.DIE_SIX, CAT_SIXES},
+	} {
+		got := dieCat(tt.i)
+		if got != tt.w {
+			t.Errorf("for dieCat(%s) got %s; want %s", tt.i, got, tt.w)
+		}
+	}
+}
+
+func TestGetRollScoreForCategory(t *testing.T) {
+	opts := []cmp.Option{
+		cmp.AllowUnexported(scoreData{}),
+	}
+	for i, tt := range []struct {
+		r [5]dice.Die
+		c Category
+		w scoreData
+	}{
+		{
+			r: [5]dice.Die{dice.DIE_THREE, dice.DIE_TWO, dice.DIE_ONE, dice.DIE_FOUR, dice.DIE_THREE},
+			c: CAT_SMALL_STRAIGHT,
+			w: scoreData{30, [][]int{{2, 1, 0, 3}, {2, 1, 4, 3}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_TWO, dice.DIE_TWO, dice.DIE_TWO, dice.DIE_THREE, dice.DIE_FOUR},
+			c: CAT_ONES,
+		},
+		{
+			r: [5]dice.Die{dice.DIE_ONE, dice.DIE_ONE, dice.DIE_TWO, dice.DIE_ONE, dice.DIE_ONE},
+			c: CAT_ONES,
+			w: scoreData{4, [][]int{{0, 1, 3, 4}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_TWO, dice.DIE_TWO, dice.DIE_TWO, dice.DIE_THREE, dice.DIE_FOUR},
+			c: CAT_TWOS,
+			w: scoreData{6, [][]int{{0, 1, 2}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_TWO, dice.DIE_TWO, dice.DIE_TWO, dice.DIE_THREE, dice.DIE_FOUR},
+			c: CAT_THREES,
+			w: scoreData{3, [][]int{{3}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_TWO, dice.DIE_TWO, dice.DIE_TWO, dice.DIE_THREE, dice.DIE_FOUR},
+			c: CAT_FOURS,
+			w: scoreData{4, [][]int{{4}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_TWO, dice.DIE_SIX, dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_FOUR},
+			c: CAT_FIVES,
+			w: scoreData{10, [][]int{{2, 3}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_TWO, dice.DIE_SIX, dice.DIE_FIVE, dice.DIE_FIVE, dice.DIE_FOUR},
+			c: CAT_SIXES,
+			w: scoreData{6, [][]int{{1}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_TWO, dice.DIE_TWO, dice.DIE_TWO, dice.DIE_THREE, dice.DIE_FOUR},
+			c: CAT_THREE_OF_A_KIND,
+			w: scoreData{13, [][]int{{0, 1, 2}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_TWO, dice.DIE_TWO, dice.DIE_TWO, dice.DIE_TWO, dice.DIE_FOUR},
+			c: CAT_THREE_OF_A_KIND,
+			w: scoreData{12, [][]int{{0, 1, 2, 3}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_ONE, dice.DIE_TWO, dice.DIE_THREE, dice.DIE_TWO, dice.DIE_FOUR},
+			c: CAT_THREE_OF_A_KIND,
+		},
+		{
+			r: [5]dice.Die{dice.DIE_ONE, dice.DIE_TWO, dice.DIE_SIX, dice.DIE_ONE, dice.DIE_ONE},
+			c: CAT_FOUR_OF_A_KIND,
+		},
+		{
+			r: [5]dice.Die{dice.DIE_ONE, dice.DIE_TWO, dice.DIE_ONE, dice.DIE_ONE, dice.DIE_ONE},
+			c: CAT_FOUR_OF_A_KIND,
+			w: scoreData{6, [][]int{{0, 2, 3, 4}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_ONE, dice.DIE_TWO, dice.DIE_ONE, dice.DIE_ONE, dice.DIE_TWO},
+			c: CAT_FULL_HOUSE,
+			w: scoreData{25, [][]int{{0, 1, 2, 3, 4}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_FIVE, dice.DIE_TWO, dice.DIE_ONE, dice.DIE_ONE, dice.DIE_TWO},
+			c: CAT_FULL_HOUSE,
+		},
+		{
+			r: [5]dice.Die{dice.DIE_THREE, dice.DIE_TWO, dice.DIE_ONE, dice.DIE_FOUR, dice.DIE_FIVE},
+			c: CAT_SMALL_STRAIGHT,
+			w: scoreData{30, [][]int{{2, 1, 0, 3}, {1, 0, 3, 4}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_THREE, dice.DIE_THREE, dice.DIE_ONE, dice.DIE_FOUR, dice.DIE_FIVE},
+			c: CAT_SMALL_STRAIGHT,
+		},
+		{
+			r: [5]dice.Die{dice.DIE_ONE, dice.DIE_TWO, dice.DIE_TWO, dice.DIE_THREE, dice.DIE_FOUR},
+			c: CAT_SMALL_STRAIGHT,
+			w: scoreData{30, [][]int{{0, 1, 3, 4}, {0, 2, 3, 4}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_SIX, dice.DIE_FOUR, dice.DIE_FIVE, dice.DIE_SIX, dice.DIE_THREE},
+			c: CAT_SMALL_STRAIGHT,
+			w: scoreData{30, [][]int{{4, 1, 2, 0}, {4, 1, 2, 3}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_SIX, dice.DIE_FOUR, dice.DIE_FIVE, dice.DIE_TWO, dice.DIE_THREE},
+			c: CAT_SMALL_STRAIGHT,
+			w: scoreData{30, [][]int{{3, 4, 1, 2}, {4, 1, 2, 0}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_ONE, dice.DIE_THREE, dice.DIE_FOUR, dice.DIE_FIVE, dice.DIE_SIX},
+			c: CAT_SMALL_STRAIGHT,
+			w: scoreData{30, [][]int{{1, 2, 3, 4}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_SIX, dice.DIE_TWO, dice.DIE_THREE, dice.DIE_FOUR, dice.DIE_FIVE},
+			c: CAT_LARGE_STRAIGHT,
+			w: scoreData{40, [][]int{{1, 2, 3, 4, 0}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_THREE, dice.DIE_TWO, dice.DIE_ONE, dice.DIE_FOUR, dice.DIE_FIVE},
+			c: CAT_LARGE_STRAIGHT,
+			w: scoreData{40, [][]int{{2, 1, 0, 3, 4}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_THREE, dice.DIE_TWO, dice.DIE_FOUR, dice.DIE_FOUR, dice.DIE_FIVE},
+			c: CAT_LARGE_STRAIGHT,
+		},
+		{
+			r: [5]dice.Die{dice.DIE_THREE, dice.DIE_TWO, dice.DIE_ONE, dice.DIE_FOUR, dice.DIE_FIVE},
+			c: CAT_CHANCE,
+			w: scoreData{15, [][]int{{0, 1, 2, 3, 4}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_THREE, dice.DIE_THREE, dice.DIE_THREE, dice.DIE_THREE, dice.DIE_THREE},
+			c: CAT_YATZY,
+			w: scoreData{50, [][]int{{0, 1, 2, 3, 4}}},
+		},
+		{
+			r: [5]dice.Die{dice.DIE_FOUR, dice.DIE_THREE, dice.DIE_THREE, dice.DIE_THREE, dice.DIE_FOUR},
+			c: CAT_YATZY,
+		},
+		{
+			r: [5]dice.Die{dice.DIE_THREE, dice.DIE_THREE, dice.DIE_THREE, dice.DIE_THREE, dice.DIE_FOUR},
+			c: CAT_YATZY,
+		},
+	} {
+		r2 := dice.RollOf(tt.r)
+		got := getScoreData(r2, tt.c)
+		if diff := cmp.Diff(got, tt.w, opts...); diff != "" {
+			t.Errorf("roll score does not match (-got, +want): input[%d] %+v, %d;\n%s", i, r2.Dice(), r2, diff)
+		}
+	}
+}
+
+// TestStraightsExhaustive checks straights in every roll against trying
+// every set of dice.
+func TestStraightsExhaustive(t *testing.T) {
+	for _, r2 := range dice.Rolls() {
+		dice := r2.Dice()
+		for _, tt := range []struct {
+			c     Category
+			n     int
+			score uint16
+		}{
+			{CAT_SMALL_STRAIGHT, 4, 30},
+			{CAT_LARGE_STRAIGHT, 5, 40},
+		} {
+			var want scoreData
+			for mask := range 1 << 5 {
+				if bits.OnesCount(uint(mask)) != tt.n {
+					continue
+				}
+				var set []int
+				for idx := range 5 {
+					if mask&(1<<idx) != 0 {
+						set = append(set, idx)
+					}
+				}
+				slices.SortStableFunc(set, func(a, b int) int { return int(dice[a]) - int(dice[b]) })
+				straight := true
+				for i := 1; i < len(set); i++ {
+					straight = straight && dice[set[i]] == dice[set[i-1]]+1
+				}
+				if straight {
+					want.score = tt.score
+					want.used = append(want.used, set)
+				}
+			}
+
+			got := getScoreData(r2, tt.c)
+			sortSets := func(sets [][]int) [][]int {
+				var sorted [][]int
+				for _, set := range sets {
+					sorted = append(sorted, slices.Sorted(slices.Values(set)))
+				}
+				slices.SortFunc(sorted, slices.Compare)
+				return sorted
+			}
+			if got.score != want.score {
+				t.Errorf("getScoreData(%s, %s).score = %d; want %d", r2, tt.c, got.score, want.score)
+			}
+			if diff := cmp.Diff(sortSets(got.used), sortSets(want.used)); diff != "" {
+				t.Errorf("getScoreData(%s, %s).used does not match (-got, +want):\n%s", r2, tt.c, diff)
+			}
+		}
+	}
+}
+
+func TestRollTables(t *testing.T) {
+	seen := make(map[dice.Roll]bool)
+	for _, r2 := range dice.SortedRolls() {
+		if seen[r2] {
+			t.Errorf("sorted roll %s appears twice", r2)
+		}
+		seen[r2] = true
+	}
+	for ord, r2 := range dice.Rolls() {
+		if got := r2.Ordinal(); got != ord {
+			t.Errorf("%s.Ordinal() = %d; want %d", r2, got, ord)
+		}
+		sorted := r2.Dice()
+		slices.Sort(sorted[:])
+		if got, want := dice.Multiset(r2.Key()).Roll(), dice.RollOf(sorted); got != want {
+			t.Errorf("Multiset(%s.Key()).Roll() = %s; want %s", r2, got, want)
+		}
+		for c := range Category(Categories) {
+			want := getScoreData(r2, c)
+			if got := RollScore(r2, c); got != want.score {
+				t.Errorf("RollScore(%s, %s) = %d; want %d", r2, c, got, want.score)
+			}
+			if want.score == 0 {
+				want.used = nil
+			}
+			var wantUsed [][]int
+			for _, used := range want.used {
+				wantUsed = append(wantUsed, slices.Sorted(slices.Values(used)))
+			}
+			if diff := cmp.Diff(rollUsed[ord][c].indices(), wantUsed); diff != "" {
+				t.Errorf("rollUsed for %s, %s does not match (-got, +want):\n%s", r2, c, diff)
+			}
+		}
+	}
+}
+
+func TestPlayerScorecardUpdate(t *testing.T) {
+	opts := []cmp.Option{
+		cmp.AllowUnexported(Scorecard{}),
+	}
+	var ps Scorecard
+
+	t.Run("seriesA", func(t *testing.T) {
+		// 1. 1, 2, 3, 4, 5 - large straight
+		got := ps.Update(dice.RollOf([5]dice.Die{dice.DIE_ONE, dice.DIE_TWO, dice.DIE_THREE, dice.DIE_FOUR, dice.DIE_FIVE}), CAT_LARGE_STRAIGHT)
+		if diff := cmp.Diff(ps, Scorecard{}, opts...); diff != "" {
+			t.Errorf("original scorecard was modified (-got, +want):\n%s", diff)
+		}
+		want := Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_LARGE_STRAIGHT: 40,
+			},
+			CatMask: 1024,
+		}
+		if diff := cmp.Diff(got, want, opts...); diff != "" {
+			t.Errorf("scorecards do not match (-got, +want):\n%s", diff)
+		}
+
+		// 2. 1, 1, 1, 1, 1 - take ones
+		got = got.Update(dice.RollOf([5]dice.Die{dice.DIE_ONE, dice.DIE_ONE, dice.DIE_ONE, dice.DIE_ONE, dice.DIE_ONE}), CAT_ONES)
+		want = Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_ONES:           5,
+				CAT_LARGE_STRAIGHT: 40,
+			},
+			CatMask: 1025,
+		}
+		if diff := cmp.Diff(got, want, opts...); diff != "" {
+			t.Errorf("scorecards do not match (-got, +want):\n%s", diff)
+		}
+
+		// 3. 2, 2, 2, 2, 2 - take yatzy
+		got = got.Update(dice.RollOf([5]dice.Die{dice.DIE_TWO, dice.DIE_TWO, dice.DIE_TWO, dice.DIE_TWO, dice.DIE_TWO}), CAT_YATZY)
+		want = Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_ONES:           5,
+				CAT_LARGE_STRAIGHT: 40,
+				CAT_YATZY:          50,
+			},
+			CatMask: 5121,
+		}
+		if diff := cmp.Diff(got, want, opts...); diff != "" {
+			t.Errorf("scorecards do not match (-got, +want):\n%s", diff)
+		}
+
+		// 4. All six - cannot use joker on full house (must take six) + see 100 bonus
+		got = got.Update(dice.RollOf([5]dice.Die{dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX, dice.DIE_SIX}), CAT_FULL_HOUSE)
+		want = Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_ONES:           5,
+				CAT_FULL_HOUSE:     0,
+				CAT_LARGE_STRAIGHT: 40,
+				CAT_YATZY:          150,
+			},
+			CatMask: 5377,
+		}
+		if diff := cmp.Diff(got, want, opts...); diff != "" {
+			t.Errorf("scorecards do not match (-got, +want):\n%s", diff)
+		}
+	})
+
+	t.Run("seriesB", func(t *testing.T) {
+		// 1. 1, 1, 1, 1, 1
+		got := ps.Update(dice.RollOf([5]dice.Die{dice.DIE_ONE, dice.DIE_ONE, dice.DIE_ONE, dice.DIE_ONE, dice.DIE_TWO}), CAT_ONES)
+		if diff := cmp.Diff(ps, Scorecard{}, opts...); diff != "" {
+			t.Errorf("original scorecard was modified (-got, +want):\n%s", diff)
+		}
+		want := Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_ONES: 4,
+			},
+			CatMask: 1,
+		}
+		if diff := cmp.Diff(got, want, opts...); diff != "" {
+			t.Errorf("scorecards do not match (-got, +want):\n%s", diff)
+		}
+
+		// 2. 1, 3, 4, 5, 6 - take yatzy
+		got = got.Update(dice.RollOf([5]dice.Die{dice.DIE_ONE, dice.DIE_TWO, dice.DIE_THREE, dice.DIE_FIVE, dice.DIE_SIX}), CAT_YATZY)
+		want = Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_ONES:  4,
+				CAT_YATZY: 0,
+			},
+			CatMask: 4097,
+		}
+		if diff := cmp.Diff(got, want, opts...); diff != "" {
+			t.Errorf("scorecards do not match (-got, +want):\n%s", diff)
+		}
+
+		// 3. 2, 2, 2, 2, 2 - take large straight
+		got = got.Update(dice.RollOf([5]dice.Die{dice.DIE_TWO, dice.DIE_TWO, dice.DIE_TWO, dice.DIE_TWO, dice.DIE_TWO}), CAT_LARGE_STRAIGHT)
+		want = Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_ONES:           4,
+				CAT_LARGE_STRAIGHT: 0,
+				CAT_YATZY:          0,
+			},
+			CatMask: 5121,
+		}
+		if diff := cmp.Diff(got, want, opts...); diff != "" {
+			t.Errorf("scorecards do not match (-got, +want):\n%s", diff)
+		}
+
+		// 4. All ones - use joker on full house
+		got = got.Update(dice.RollOf([5]dice.Die{dice.DIE_ONE, dice.DIE_ONE, dice.DIE_ONE, dice.DIE_ONE, dice.DIE_ONE}), CAT_FULL_HOUSE)
+		want = Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_ONES:           4,
+				CAT_FULL_HOUSE:     25,
+				CAT_LARGE_STRAIGHT: 0,
+				CAT_YATZY:          0,
+			},
+			CatMask: 5377,
+		}
+		if diff := cmp.Diff(got, want, opts...); diff != "" {
+			t.Errorf("scorecards do not match (-got, +want):\n%s", diff)
+		}
+	})
+}
+
+func TestGetTurnsLeft(t *testing.T) {
+	ps := Scorecard{
+		ScoresByCategory: [13]uint16{
+			CAT_ONES:           5,
+			CAT_LARGE_STRAIGHT: 40,
+		},
+		CatMask: 1025,
+	}
+	if got, want := ps.GetTurnsLeft(), 11; got != want {
+		t.Errorf("turns left do not match: got %d; want %d", got, want)
+	}
+}
+
+func TestPlayerScorecardGetNext(t *testing.T) {
+	opts := []cmp.Option{
+		cmp.AllowUnexported(Scorecard{}),
+	}
+	var ps Scorecard
+	got := ps.GetNext(dice.RollOf([5]dice.Die{dice.DIE_ONE, dice.DIE_TWO, dice.DIE_THREE, dice.DIE_FOUR, dice.DIE_FIVE}), nil)
+	want := []Scorecard{
+		Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_ONES: 1,
+			},
+			CatMask: 1,
+		},
+		Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_TWOS: 2,
+			},
+			CatMask: 2,
+		},
+		Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_THREES: 3,
+			},
+			CatMask: 4,
+		},
+		Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_FOURS: 4,
+			},
+			CatMask: 8,
+		},
+		Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_FIVES: 5,
+			},
+			CatMask: 16,
+		},
+		Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_SIXES: 0,
+			},
+			CatMask: 32,
+		},
+		Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_THREE_OF_A_KIND: 0,
+			},
+			CatMask: 64,
+		},
+		Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_FOUR_OF_A_KIND: 0,
+			},
+			CatMask: 128,
+		},
+		Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_FULL_HOUSE: 0,
+			},
+			CatMask: 256,
+		},
+		Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_SMALL_STRAIGHT: 30,
+			},
+			CatMask: 512,
+		},
+		Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_LARGE_STRAIGHT: 40,
+			},
+			CatMask: 1024,
+		},
+		Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_CHANCE: 15,
+			},
+			CatMask: 2048,
+		},
+		Scorecard{
+			ScoresByCategory: [13]uint16{
+				CAT_YATZY: 0,
+			},
+			CatMask: 4096,
+		},
+	}
+	if diff := cmp.Diff(got, want, opts...); diff != "" {
+		t.Errorf("next scorecards do not match (-got, +want):\n%s", diff)
+	}
+}
+
+func TestPlayerScorecardScore(t *testing.T) {
+	ps := Scorecard{
+		ScoresByCategory: [13]uint16{
+			CAT_ONES:            4,
+			CAT_TWOS:            6,
+			CAT_THREES:          12,
+			CAT_FOURS:           16,
+			CAT_FIVES:           20,
+			CAT_SIXES:           18,
+			CAT_THREE_OF_A_KIND: 28,
+			CAT_FOUR_OF_A_KIND:  29,
+			CAT_FULL_HOUSE:      25,
+			CAT_YATZY:           50,
+		},
+		CatMask: 0x1FFF,
+	}
+
+	if got, want := ps.Score(), uint16(243); got != want {
+		t.Errorf("player scorecard scores do not match: got %d; want %d", got, want)
+	}
+}
+
+func TestMaxTheoreticalScore(t *testing.T) {
+	for _, tt := range []struct {
+		ps   Scorecard
+		want uint16
+	}{
+		{
+			ps: Scorecard{
+				ScoresByCategory: [13]uint16{
+					CAT_TWOS:   4,
+					CAT_THREES: 6,
+					CAT_FOURS:  12,
+					CAT_FIVES:  15,
+					CAT_SIXES:  18,
+				},
+				CatMask: 0xFFE,
+			},
+			want: 210,
+		},
+		{
+			ps: Scorecard{
+				ScoresByCategory: [13]uint16{
+					CAT_THREES: 6,
+					CAT_FOURS:  12,
+					CAT_FIVES:  15,
+					CAT_SIXES:  18,
+				},
+				CatMask: 0xFFC,
+			},
+			want: 351,
+		},
+		{
+			ps: Scorecard{
+				ScoresByCategory: [13]uint16{
+					CAT_THREES: 6,
+					CAT_FOURS:  12,
+					CAT_FIVES:  15,
+					CAT_SIXES:  18,
+					CAT_YATZY:  50,
+				},
+				CatMask: 0x1FFC,
+			},
+			// Both turns left can be yatzy bonuses.
+			want: 351,
+		},
+		{
+			ps: Scorecard{
+				CatMask: 0x1FFF,
+			},
+			want: 0,
+		},
+		{
+			ps: Scorecard{
+				CatMask: 0x1FFE,
+			},
+			want: 5,
+		},
+		{
+			ps: Scorecard{
+				CatMask: 0x1FFC,
+			},
+			want: 15,
+		},
+		{
+			ps: Scorecard{
+				CatMask: 0x17FF,
+			},
+			want: 30,
+		},
+		{
+			ps: Scorecard{
+				CatMask: 0xFFF,
+			},
+			want: 50,
+		},
+	} {
+		got := tt.ps.MaxTheoreticalScore()
+		if got != tt.want {
+			t.Errorf("ps:\n%s\ngot %d; want %d", tt.ps.Pretty(), got, tt.want)
+		}
+	}
+}
+
+func BenchmarkPlayerScorecardUpdate(b *testing.B) {
+	r := rand.New(rand.NewPCG(1, 2))
+	var rs [1024]dice.Roll
+	for i := range rs {
+		rs[i] = dice.RollAt(r.IntN(dice.OrderedRolls))
+	}
+	var ps Scorecard
+	b.ResetTimer()
+	for i := range b.N {
+		ps = ps.Update(rs[i%len(rs)], Category(i%Categories))
+	}
+	_ = ps
+}
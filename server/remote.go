@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/game"
+)
+
+// remotePlayer is a human playing over a WebSocket. It waits for moves
+// from whoever holds its seat until the context PickMove is given is done,
+// then makes a move for them.
+type remotePlayer struct {
+	t   *table
+	idx int
+	// timedOut is whether the last move was made for the human.
+	timedOut bool
+}
+
+func (rp *remotePlayer) String() string { return fmt.Sprintf("remote player %d", rp.idx+1) }
+
+func (rp *remotePlayer) PickMove(ctx context.Context, g *game.Game, moves []game.Move) int {
+	s := rp.t.seats[rp.idx]
+	rp.t.mu.Lock()
+	s.waiting = true
+	// Drop moves sent too late for an earlier decision.
+	for len(s.moves) > 0 {
+		<-s.moves
+	}
+	rp.t.mu.Unlock()
+	defer func() {
+		rp.t.mu.Lock()
+		s.waiting = false
+		rp.t.mu.Unlock()
+	}()
+
+	rp.timedOut = false
+	for {
+		select {
+		case <-ctx.Done():
+			rp.timedOut = true
+			return bestSelection(moves)
+		case sub := <-s.moves:
+			moveIdx, err := rp.move(g, moves, sub.msg)
+			if err != nil {
+				rp.t.mu.Lock()
+				rp.t.sendErrorLocked(sub.from, err)
+				rp.t.mu.Unlock()
+				continue
+			}
+			if moveIdx < 0 {
+				rp.t.publish()
+				continue
+			}
+			return moveIdx
+		}
+	}
+}
+
+// parseHold parses the dice a client holds. Too many dice are rejected
+// before they are counted, as the counts of a face would overflow.
+func parseHold(vals []int) ([]dice.Die, error) {
+	held, err := dice.ParseValues(vals)
+	if err != nil {
+		return nil, err
+	}
+	if len(held) >= 5 {
+		return nil, fmt.Errorf("%w: must leave at least one die to reroll", dice.ErrInvalidHold)
+	}
+	return held, nil
+}
+
+// move returns the index of the move msg asks for in moves, or -1 if msg
+// only marks dice to hold.
+func (rp *remotePlayer) move(g *game.Game, moves []game.Move, msg clientMessage) (int, error) {
+	var m game.Move
+	switch msg.Type {
+	case msgHold:
+		held, err := parseHold(msg.Hold)
+		if err != nil {
+			return 0, err
+		}
+		// Marking nothing clears the marks.
+		if len(held) > 0 {
+			if err := dice.ValidateHold(g.CurTurn.CurrentRoll, dice.Count(held...)); err != nil {
+				return 0, err
+			}
+		}
+		rp.t.held = held
+		return -1, nil
+	case msgRoll:
+		held := rp.t.held
+		if msg.Hold != nil {
+			var err error
+			if held, err = parseHold(msg.Hold); err != nil {
+				return 0, err
+			}
+		}
+		if len(held) == 0 {
+			// Rerolling every die is not a move.
+			return 0, fmt.Errorf("%w: mark dice with hold, or send them with the roll", game.ErrEmptyHold)
+		}
+		m = game.NewRerollMove(held...)
+	case msgSelect:
+		if msg.Category == nil {
+			return 0, fmt.Errorf("%w: select needs a category", game.ErrInvalidSelection)
+		}
+		m = g.SelectMove(*msg.Category)
+	default:
+		return 0, fmt.Errorf("%w: %q (want hold, roll or select)", game.ErrUnknownMove, msg.Type)
+	}
+	if err := g.ValidateMove(m); err != nil {
+		return 0, err
+	}
+	for i, mv := range moves {
+		if mv.Reroll == m.Reroll && mv.Hold == m.Hold && mv.Cat == m.Cat {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", game.ErrUnknownMove, m)
+}
+
+// bestSelection returns the index of the highest scoring category in
+// moves, the move made for a human who runs out of time.
+func bestSelection(moves []game.Move) int {
+	best := -1
+	for i, m := range moves {
+		if !m.Reroll && (best < 0 || m.Score > moves[best].Score) {
+			best = i
+		}
+	}
+	return best
+}
@@ -0,0 +1,326 @@
+// Package server hosts multiplayer games. Seats are filled by remote
+// humans, who play over a WebSocket, or by built-in players, and anyone
+// can watch.
+//
+// A table is created with a JSON POST listing its seats in turn order:
+//
+//	POST /tables {"seats": ["human", "mc"]}
+//
+// which answers with the table's id. Humans take a seat, and spectators
+// watch, over a WebSocket:
+//
+//	GET /tables/{id}/ws?seat=0           take seat 0
+//	GET /tables/{id}/ws?seat=0&token=... take seat 0 again after disconnecting
+//	GET /tables/{id}/ws                  watch
+//
+// The game starts once every human seat has been taken. A human who runs
+// out of time on a decision, connected or not, has the highest scoring
+// category selected for them. The messages exchanged are documented in
+// table.go, and positions use the schema documented in package game.
+//
+// Tables are forgotten a while after their game ends, or if their humans
+// never all take their seats, and only so many can be open at once.
+// WebSockets can only be opened from web pages served by the server's
+// own host, or from the origins it allows.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	mrand "math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/solver"
+	"github.com/AustinJGreen/goyatzy/strategy"
+	"github.com/AustinJGreen/goyatzy/value"
+)
+
+// botKinds creates each kind of built-in player a seat can be filled
+// with. Players share the rng of the game they are playing in.
+var botKinds = map[string]func(s *Server, rng *mrand.Rand) game.Player{
+	"random": func(_ *Server, rng *mrand.Rand) game.Player { return &strategy.RandomPlayer{Rng: rng} },
+	"mc": func(_ *Server, rng *mrand.Rand) game.Player {
+		return &strategy.MonteCarloPlayer{Rng: rng, Endgame: solver.Default, Racing: strategy.DefaultRacing}
+	},
+	"value": func(s *Server, _ *mrand.Rand) game.Player { return &value.Player{Model: s.model()} },
+}
+
+// seatKindNames lists the kinds of seat, for error messages.
+func seatKindNames() string {
+	names := []string{humanKind}
+	for name := range botKinds {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+// maxSeats is the most seats a table can have.
+const maxSeats = 8
+
+// Defaults for the limits on tables.
+const (
+	DefaultMaxTables = 100
+	DefaultTableTTL  = 10 * time.Minute
+)
+
+// Server hosts tables. Create one with New.
+type Server struct {
+	// TurnTimeout is how long a human has for each decision.
+	TurnTimeout time.Duration
+	// BotThink is how long a built-in player thinks for each decision.
+	BotThink time.Duration
+	// NewSource returns the source of the dice rolled at a new table.
+	NewSource func() *mrand.PCG
+	// MaxTables is the most tables that can be open at once.
+	MaxTables int
+	// TableTTL is how long a table is kept after its game ends, and how
+	// long it waits for its humans to take their seats.
+	TableTTL time.Duration
+	// AllowedOrigins are the origins, such as "https://example.com", of
+	// web pages served elsewhere that may open a WebSocket.
+	AllowedOrigins []string
+	// Model is the model value seats play with, or value.Default() if nil.
+	Model *value.Model
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	mux    *http.ServeMux
+
+	mu     sync.Mutex
+	tables map[string]*table
+	nextID int
+}
+
+// New returns a server that gives humans turnTimeout and built-in players
+// botThink for each decision, with the default limits on tables.
+func New(turnTimeout, botThink time.Duration) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		TurnTimeout: turnTimeout,
+		BotThink:    botThink,
+		NewSource: func() *mrand.PCG {
+			return mrand.NewPCG(uint64(time.Now().UnixNano()), 0)
+		},
+		MaxTables: DefaultMaxTables,
+		TableTTL:  DefaultTableTTL,
+		ctx:       ctx,
+		cancel:    cancel,
+		mux:       http.NewServeMux(),
+		tables:    make(map[string]*table),
+	}
+	s.mux.HandleFunc("POST /tables", s.handleCreate)
+	s.mux.HandleFunc("GET /tables/{id}", s.handleState)
+	s.mux.HandleFunc("GET /tables/{id}/ws", s.handleConnect)
+	return s
+}
+
+func (s *Server) model() *value.Model {
+	if s.Model != nil {
+		return s.Model
+	}
+	return value.Default()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Close stops every game. Connections are left to the http.Server.
+func (s *Server) Close() {
+	s.cancel()
+}
+
+type createRequest struct {
+	Seats []string `json:"seats"`
+}
+
+type createResponse struct {
+	ID string `json:"id"`
+}
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req createRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxMessageBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Seats) == 0 || len(req.Seats) > maxSeats {
+		http.Error(w, fmt.Sprintf("a table needs between 1 and %d seats", maxSeats), http.StatusBadRequest)
+		return
+	}
+
+	g := game.New(s.NewSource(), make([]game.Player, len(req.Seats)))
+	seats := make([]*seat, len(req.Seats))
+	for i, kind := range req.Seats {
+		seats[i] = &seat{kind: kind}
+		if newBot, ok := botKinds[kind]; ok {
+			seats[i].player = newBot(s, g.Rng)
+		} else if kind != humanKind {
+			http.Error(w, fmt.Sprintf("unknown seat kind %q (want one of %s)", kind, seatKindNames()), http.StatusBadRequest)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	s.expireLocked(time.Now())
+	if len(s.tables) >= s.MaxTables {
+		s.mu.Unlock()
+		http.Error(w, fmt.Sprintf("too many tables open (at most %d)", s.MaxTables), http.StatusServiceUnavailable)
+		return
+	}
+	s.nextID++
+	id := strconv.Itoa(s.nextID)
+	t := newTable(s.ctx, id, g, seats, s.TurnTimeout, s.BotThink)
+	for i, st := range seats {
+		if st.kind == humanKind {
+			st.player = &remotePlayer{t: t, idx: i}
+			st.moves = make(chan submission, 8)
+		}
+		g.Players[i] = st.player
+	}
+	s.tables[id] = t
+	s.mu.Unlock()
+	t.open()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createResponse{ID: id})
+}
+
+// expireLocked forgets the tables that have expired at now, dropping
+// their clients.
+func (s *Server) expireLocked(now time.Time) {
+	for id, t := range s.tables {
+		if t.expired(now, s.TableTTL) {
+			delete(s.tables, id)
+			t.close()
+		}
+	}
+}
+
+func (s *Server) table(id string) *table {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables[id]
+}
+
+// handleState answers with the latest state of a table, for clients that
+// poll rather than connect.
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	t := s.table(r.PathValue("id"))
+	if t == nil {
+		http.NotFound(w, r)
+		return
+	}
+	t.mu.Lock()
+	st := t.state
+	for _, s := range t.seats {
+		st.Seats = append(st.Seats, seatState{Kind: s.kind, Connected: s.client != nil})
+	}
+	data, err := json.Marshal(st)
+	t.mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+var (
+	errSeatTaken = errors.New("seat is taken")
+	errBadToken  = errors.New("wrong token for seat")
+)
+
+// claim checks that a connection may take seat idx with token, returning
+// the token the seat will have.
+func (t *table) claim(idx int, token string) (string, int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if idx < 0 || idx >= len(t.seats) || t.seats[idx].kind != humanKind {
+		return "", http.StatusBadRequest, fmt.Errorf("no human seat %d", idx)
+	}
+	s := t.seats[idx]
+	switch {
+	case s.token == "" && token == "":
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return "", http.StatusInternalServerError, err
+		}
+		return hex.EncodeToString(b), 0, nil
+	case s.token == "":
+		return "", http.StatusForbidden, errBadToken
+	case token == "":
+		return "", http.StatusConflict, errSeatTaken
+	case token != s.token:
+		return "", http.StatusForbidden, errBadToken
+	}
+	return token, 0, nil
+}
+
+// allowOrigin reports whether a WebSocket may be opened by r. Browsers
+// send the origin of the page opening it, which must be the server's own
+// host or one of AllowedOrigins, so that other pages open in a browser
+// cannot take a seat. Other clients send no origin.
+func (s *Server) allowOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || slices.Contains(s.AllowedOrigins, origin) {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+func (s *Server) handleConnect(w http.ResponseWriter, r *http.Request) {
+	t := s.table(r.PathValue("id"))
+	if t == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if !s.allowOrigin(r) {
+		http.Error(w, fmt.Sprintf("origin %q is not allowed", r.Header.Get("Origin")), http.StatusForbidden)
+		return
+	}
+	c := &client{send: make(chan []byte, clientQueue), seat: -1}
+	var token string
+	if seatArg := r.URL.Query().Get("seat"); seatArg != "" {
+		idx, err := strconv.Atoi(seatArg)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("bad seat %q", seatArg), http.StatusBadRequest)
+			return
+		}
+		var code int
+		if token, code, err = t.claim(idx, r.URL.Query().Get("token")); err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		c.seat = idx
+	}
+
+	ws, err := upgrade(w, r)
+	if err != nil {
+		return
+	}
+	c.ws = ws
+	if err := t.join(c, token); err != nil {
+		data, _ := json.Marshal(errorMessage{Type: msgError, Error: err.Error()})
+		ws.WriteMessage(data)
+		ws.Close()
+		return
+	}
+	t.serve(c)
+}
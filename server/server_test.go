@@ -0,0 +1,388 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+func newTestServer(t *testing.T, turnTimeout time.Duration) *httptest.Server {
+	s := New(turnTimeout, 10*time.Millisecond)
+	s.NewSource = func() *rand.PCG { return rand.NewPCG(1, 2) }
+	ts := httptest.NewServer(s)
+	t.Cleanup(func() {
+		s.Close()
+		ts.Close()
+	})
+	return ts
+}
+
+// createTable creates a table with seats and returns its id.
+func createTable(t *testing.T, ts *httptest.Server, seats ...string) string {
+	t.Helper()
+	body, _ := json.Marshal(createRequest{Seats: seats})
+	resp, err := http.Post(ts.URL+"/tables", "application/json", strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("POST /tables returned status %d; want %d", resp.StatusCode, http.StatusCreated)
+	}
+	var cr createResponse
+	if err := json.NewDecoder(resp.Body).Decode(&cr); err != nil {
+		t.Fatal(err)
+	}
+	return cr.ID
+}
+
+// dial opens a WebSocket to path, returning the status code instead if
+// the server refuses.
+func dial(t *testing.T, ts *httptest.Server, path string) (*wsConn, int) {
+	t.Helper()
+	return dialFrom(t, ts, path, "")
+}
+
+// dialFrom is dial from a web page at origin, if set.
+func dialFrom(t *testing.T, ts *httptest.Server, path, origin string) (*wsConn, int) {
+	t.Helper()
+	conn, err := net.Dial("tcp", ts.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if err := req.Write(conn); err != nil {
+		t.Fatal(err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, resp.StatusCode
+	}
+	if got, want := resp.Header.Get("Sec-WebSocket-Accept"), acceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != want {
+		t.Fatalf("Sec-WebSocket-Accept = %q; want %q", got, want)
+	}
+	return &wsConn{conn: conn, br: br, client: true}, resp.StatusCode
+}
+
+// serverMessage holds any message from the server.
+type serverMessage struct {
+	stateMessage
+	Seat  int    `json:"seat"`
+	Token string `json:"token"`
+	Error string `json:"error"`
+}
+
+func read(t *testing.T, ws *wsConn) serverMessage {
+	t.Helper()
+	ws.conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	data, err := ws.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() returned unexpected error: %v", err)
+	}
+	var msg serverMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+	return msg
+}
+
+func send(t *testing.T, ws *wsConn, msg string) {
+	t.Helper()
+	if err := ws.WriteMessage([]byte(msg)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readUntil reads messages until one satisfies done.
+func readUntil(t *testing.T, ws *wsConn, done func(serverMessage) bool) serverMessage {
+	t.Helper()
+	for {
+		if msg := read(t, ws); done(msg) {
+			return msg
+		}
+	}
+}
+
+func isOver(msg serverMessage) bool { return msg.Type == msgState && msg.Over }
+
+func TestHumanAgainstBot(t *testing.T) {
+	ts := newTestServer(t, 10*time.Second)
+	id := createTable(t, ts, "human", "random")
+	spectator, _ := dial(t, ts, "/tables/"+id+"/ws")
+	human, code := dial(t, ts, "/tables/"+id+"/ws?seat=0")
+	if human == nil {
+		t.Fatalf("taking seat 0 returned status %d", code)
+	}
+	if msg := read(t, human); msg.Type != msgWelcome || msg.Seat != 0 || msg.Token == "" {
+		t.Fatalf("first message = %+v; want a welcome to seat 0 with a token", msg)
+	}
+
+	// Hold the first die and roll once, then select the first open
+	// category, every turn.
+	for {
+		msg := readUntil(t, human, func(msg serverMessage) bool {
+			return msg.Type == msgError || isOver(msg) ||
+				msg.Type == msgState && msg.Game.CurPlayerIdx == 0 && msg.Deadline != nil
+		})
+		if msg.Type == msgError {
+			t.Fatalf("move rejected: %s", msg.Error)
+		}
+		if msg.Over {
+			break
+		}
+		if msg.Game.CurTurn.RollCnt == 1 {
+			send(t, human, fmt.Sprintf(`{"type": "hold", "hold": [%d]}`, msg.Game.CurTurn.CurrentRoll.Die(0)))
+			send(t, human, `{"type": "roll"}`)
+			msg = readUntil(t, human, func(msg serverMessage) bool {
+				return msg.Type == msgError || msg.Type == msgState && msg.Game.CurTurn.RollCnt == 2
+			})
+			if msg.Type == msgError {
+				t.Fatalf("roll rejected: %s", msg.Error)
+			}
+		}
+		ps := msg.Game.Scorecards[0]
+		for c := range scoring.Category(scoring.Categories) {
+			if ps.CatMask&(1<<c) == 0 {
+				send(t, human, fmt.Sprintf(`{"type": "select", "category": %q}`, c))
+				break
+			}
+		}
+	}
+
+	final := readUntil(t, spectator, isOver)
+	for i, ps := range final.Game.Scorecards {
+		if ps.CatMask != scoring.AllFilled {
+			t.Errorf("player %d finished with categories %b; want all filled", i+1, ps.CatMask)
+		}
+	}
+	if final.LastMove == nil || final.LastMove.Player != 1 || final.LastMove.TimedOut {
+		t.Errorf("last move = %+v; want the bot's", final.LastMove)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	ts := newTestServer(t, 10*time.Second)
+	id := createTable(t, ts, "human", "human")
+	first, _ := dial(t, ts, "/tables/"+id+"/ws?seat=0")
+	token := read(t, first).Token
+
+	for _, tc := range []struct {
+		path string
+		want int
+	}{
+		{"/tables/" + id + "/ws?seat=0", http.StatusConflict},
+		{"/tables/" + id + "/ws?seat=0&token=nope", http.StatusForbidden},
+		{"/tables/" + id + "/ws?seat=1&token=" + token, http.StatusForbidden},
+		{"/tables/" + id + "/ws?seat=2", http.StatusBadRequest},
+		{"/tables/99/ws", http.StatusNotFound},
+	} {
+		if ws, code := dial(t, ts, tc.path); ws != nil || code != tc.want {
+			t.Errorf("GET %s returned status %d; want %d", tc.path, code, tc.want)
+		}
+	}
+
+	// Moving out of turn is rejected.
+	second, _ := dial(t, ts, "/tables/"+id+"/ws?seat=1")
+	readUntil(t, second, func(msg serverMessage) bool { return msg.Type == msgState && msg.Started })
+	send(t, second, `{"type": "select", "category": "chance"}`)
+	if msg := readUntil(t, second, func(msg serverMessage) bool { return msg.Type == msgError }); !strings.Contains(msg.Error, "not your turn") {
+		t.Errorf("moving out of turn returned error %q; want not your turn", msg.Error)
+	}
+
+	// Reconnecting replaces the first connection, and the game goes on.
+	again, code := dial(t, ts, "/tables/"+id+"/ws?seat=0&token="+token)
+	if again == nil {
+		t.Fatalf("reconnecting returned status %d", code)
+	}
+	if msg := read(t, again); msg.Type != msgWelcome || msg.Token != token {
+		t.Errorf("reconnecting got %+v; want a welcome with the same token", msg)
+	}
+	readUntil(t, again, func(msg serverMessage) bool { return msg.Type == msgState && msg.Deadline != nil })
+	send(t, again, `{"type": "select", "category": "chance"}`)
+	msg := readUntil(t, again, func(msg serverMessage) bool { return msg.Type == msgError || msg.LastMove != nil })
+	if msg.Type == msgError || msg.LastMove.Move.Cat != scoring.CAT_CHANCE {
+		t.Errorf("move after reconnecting got %+v; want chance selected", msg)
+	}
+}
+
+func TestTurnTimeout(t *testing.T) {
+	ts := newTestServer(t, 20*time.Millisecond)
+	id := createTable(t, ts, "human")
+	ws, _ := dial(t, ts, "/tables/"+id+"/ws?seat=0")
+
+	// A human who never moves has the best category selected for them.
+	final := readUntil(t, ws, isOver)
+	if final.LastMove == nil || !final.LastMove.TimedOut {
+		t.Errorf("last move = %+v; want one made after timing out", final.LastMove)
+	}
+	if final.Game.Scorecards[0].CatMask != scoring.AllFilled {
+		t.Errorf("finished with categories %b; want all filled", final.Game.Scorecards[0].CatMask)
+	}
+}
+
+func TestCreateTableErrors(t *testing.T) {
+	ts := newTestServer(t, time.Second)
+	for _, body := range []string{
+		`{"seats": []}`,
+		`{"seats": ["human", "robot"]}`,
+		`{"seats": ["random", "random", "random", "random", "random", "random", "random", "random", "random"]}`,
+		`{"players": ["human"]}`,
+	} {
+		resp, err := http.Post(ts.URL+"/tables", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("POST /tables %s returned status %d; want %d", body, resp.StatusCode, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestTableLimits(t *testing.T) {
+	s := New(time.Second, time.Millisecond)
+	s.MaxTables = 1
+	s.TableTTL = 200 * time.Millisecond
+	ts := httptest.NewServer(s)
+	t.Cleanup(func() {
+		s.Close()
+		ts.Close()
+	})
+	create := func() int {
+		resp, err := http.Post(ts.URL+"/tables", "application/json", strings.NewReader(`{"seats": ["random"]}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// A table waiting for its human counts until it is abandoned.
+	waiting := createTable(t, ts, "human")
+	if code := create(); code != http.StatusServiceUnavailable {
+		t.Errorf("creating a table over the limit returned status %d; want %d", code, http.StatusServiceUnavailable)
+	}
+	time.Sleep(s.TableTTL)
+	if code := create(); code != http.StatusCreated {
+		t.Errorf("creating a table after the last was abandoned returned status %d; want %d", code, http.StatusCreated)
+	}
+	resp, err := http.Get(ts.URL + "/tables/" + waiting)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET abandoned table returned status %d; want %d", resp.StatusCode, http.StatusNotFound)
+	}
+
+	// A finished table is kept for a while, then forgotten.
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		resp, err := http.Get(ts.URL + "/tables/2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var st stateMessage
+		err = json.NewDecoder(resp.Body).Decode(&st)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.Over {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("bot game did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(s.TableTTL)
+	if code := create(); code != http.StatusCreated {
+		t.Errorf("creating a table after the last finished returned status %d; want %d", code, http.StatusCreated)
+	}
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	s := New(time.Second, time.Millisecond)
+	s.AllowedOrigins = []string{"https://friend.example"}
+	ts := httptest.NewServer(s)
+	t.Cleanup(func() {
+		s.Close()
+		ts.Close()
+	})
+	id := createTable(t, ts, "human", "human")
+	for _, tc := range []struct {
+		origin string
+		want   int
+	}{
+		{"", http.StatusSwitchingProtocols},
+		{ts.URL, http.StatusSwitchingProtocols},
+		{"https://friend.example", http.StatusSwitchingProtocols},
+		{"https://evil.example", http.StatusForbidden},
+		{"null", http.StatusForbidden},
+	} {
+		if _, code := dialFrom(t, ts, "/tables/"+id+"/ws", tc.origin); code != tc.want {
+			t.Errorf("connecting from origin %q returned status %d; want %d", tc.origin, code, tc.want)
+		}
+	}
+}
+
+func TestRemoteMoveHolds(t *testing.T) {
+	g := game.New(rand.NewPCG(1, 2), make([]game.Player, 1))
+	if err := g.EnterRoll(0, dice.NewRoll(5, 2, 5, 1, 3)); err != nil {
+		t.Fatal(err)
+	}
+	moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+	rp := &remotePlayer{t: &table{}}
+
+	if _, err := rp.move(g, moves, clientMessage{Type: msgRoll}); !errors.Is(err, game.ErrEmptyHold) {
+		t.Errorf("rolling with nothing held returned %v; want %v", err, game.ErrEmptyHold)
+	}
+	if _, err := rp.move(g, moves, clientMessage{Type: msgHold, Hold: []int{5, 5}}); err != nil {
+		t.Fatalf("holding the fives returned unexpected error: %v", err)
+	}
+	if idx, err := rp.move(g, moves, clientMessage{Type: msgHold, Hold: []int{}}); err != nil || idx != -1 || len(rp.t.held) != 0 {
+		t.Errorf("clearing the held dice returned %d, %v and held %v; want -1, no error and nothing held", idx, err, rp.t.held)
+	}
+	for _, hold := range [][]int{{5, 5, 5, 5, 5, 5}, {1, 1, 1, 1, 1, 1, 1, 1, 5, 5}} {
+		for _, typ := range []string{msgHold, msgRoll} {
+			if _, err := rp.move(g, moves, clientMessage{Type: typ, Hold: hold}); !errors.Is(err, dice.ErrInvalidHold) {
+				t.Errorf("%s %v returned %v; want %v", typ, hold, err, dice.ErrInvalidHold)
+			}
+		}
+	}
+	if _, err := rp.move(g, moves, clientMessage{Type: msgRoll, Hold: []int{}}); !errors.Is(err, game.ErrEmptyHold) {
+		t.Errorf("rolling with an empty hold returned %v; want %v", err, game.ErrEmptyHold)
+	}
+	idx, err := rp.move(g, moves, clientMessage{Type: msgRoll, Hold: []int{5}})
+	if err != nil {
+		t.Fatalf("rolling holding a five returned unexpected error: %v", err)
+	}
+	if want := game.NewRerollMove(dice.DIE_FIVE); moves[idx].Hold != want.Hold || !moves[idx].Reroll {
+		t.Errorf("rolling holding a five picked %s; want %s", moves[idx], want)
+	}
+}
@@ -0,0 +1,417 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+// humanKind is the kind of seat filled by a remote human.
+const humanKind = "human"
+
+// Messages sent by clients, as {"type": ...}:
+//
+//	{"type": "hold", "hold": [5, 5]}    mark dice to keep, for everyone to see
+//	{"type": "hold", "hold": []}        clear the marks
+//	{"type": "roll"}                    reroll the dice not marked
+//	{"type": "roll", "hold": [5, 5]}    reroll the dice not in hold
+//	{"type": "select", "category": "fives"}
+//
+// A roll must keep at least one die: rerolling all five is not a move.
+//
+// Messages sent by the server:
+//
+//	{"type": "welcome", "seat": 0, "token": "..."}  on taking a seat
+//	{"type": "state", ...}                           see stateMessage
+//	{"type": "error", "error": "..."}               a message was rejected
+const (
+	msgHold    = "hold"
+	msgRoll    = "roll"
+	msgSelect  = "select"
+	msgWelcome = "welcome"
+	msgState   = "state"
+	msgError   = "error"
+)
+
+type clientMessage struct {
+	Type     string            `json:"type"`
+	Hold     []int             `json:"hold"`
+	Category *scoring.Category `json:"category"`
+}
+
+type welcomeMessage struct {
+	Type string `json:"type"`
+	Seat int    `json:"seat"`
+	// Token lets the human take the seat again after disconnecting.
+	Token string `json:"token"`
+}
+
+type errorMessage struct {
+	Type  string `json:"type"`
+	Error string `json:"error"`
+}
+
+type seatState struct {
+	Kind      string `json:"kind"`
+	Connected bool   `json:"connected,omitempty"`
+}
+
+type lastMove struct {
+	Player int       `json:"player"`
+	Move   game.Move `json:"move"`
+	// TimedOut is whether the move was made for a human who ran out of
+	// time.
+	TimedOut bool `json:"timedOut,omitempty"`
+}
+
+// stateMessage is sent to every client whenever anything at the table
+// changes.
+type stateMessage struct {
+	Type  string      `json:"type"`
+	Game  *game.Game  `json:"game"`
+	Seats []seatState `json:"seats"`
+	// Held is the dice the current player has marked to keep.
+	Held []int `json:"held"`
+	// Deadline is when a human to move runs out of time.
+	Deadline *time.Time `json:"deadline,omitempty"`
+	LastMove *lastMove  `json:"lastMove,omitempty"`
+	// Started is whether every human seat has been taken, which starts
+	// the game.
+	Started bool `json:"started"`
+	Over    bool `json:"over"`
+}
+
+// client is a connection to a human or spectator at a table.
+type client struct {
+	ws *wsConn
+	// send queues messages for the connection's writer.
+	send chan []byte
+	seat int // -1 for spectators
+}
+
+// clientQueue is how many messages can be waiting to be written to a
+// client before it is dropped for being too slow.
+const clientQueue = 32
+
+// submission is a message from the human in a seat.
+type submission struct {
+	from *client
+	msg  clientMessage
+}
+
+type seat struct {
+	kind   string
+	player game.Player
+	// token is the secret a human uses to take the seat, set when the
+	// seat is first taken.
+	token  string
+	client *client
+	// waiting is whether the seat's remote player is waiting for a move,
+	// so messages are accepted into moves.
+	waiting bool
+	moves   chan submission
+}
+
+// table hosts a game. The game itself is only touched by run and the
+// players it calls; everything else is guarded by mu.
+type table struct {
+	id          string
+	g           *game.Game
+	turnTimeout time.Duration
+	botThink    time.Duration
+	ctx         context.Context
+	cancel      context.CancelFunc
+	created     time.Time
+
+	// Owned by run.
+	held     []dice.Die
+	last     *lastMove
+	deadline time.Time
+
+	mu      sync.Mutex
+	seats   []*seat
+	clients map[*client]bool
+	started bool
+	// ended is when run returned, or zero if it has not.
+	ended time.Time
+	state stateMessage
+}
+
+func newTable(ctx context.Context, id string, g *game.Game, seats []*seat, turnTimeout, botThink time.Duration) *table {
+	ctx, cancel := context.WithCancel(ctx)
+	return &table{
+		id:          id,
+		g:           g,
+		turnTimeout: turnTimeout,
+		botThink:    botThink,
+		ctx:         ctx,
+		cancel:      cancel,
+		created:     time.Now(),
+		seats:       seats,
+		clients:     make(map[*client]bool),
+	}
+}
+
+// open makes the table ready for clients, once its players are seated.
+func (t *table) open() {
+	t.publish()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.startIfReadyLocked()
+}
+
+// startIfReadyLocked starts the game once every human seat has been
+// taken.
+func (t *table) startIfReadyLocked() {
+	if t.started {
+		return
+	}
+	for _, s := range t.seats {
+		if s.kind == humanKind && s.token == "" {
+			return
+		}
+	}
+	t.started = true
+	t.state.Started = true
+	t.broadcastLocked()
+	go t.run()
+}
+
+// expired reports whether t can be forgotten at now: ttl after its game
+// ended, or after it was created if its humans never all took their
+// seats.
+func (t *table) expired(now time.Time, ttl time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch {
+	case !t.ended.IsZero():
+		return now.Sub(t.ended) >= ttl
+	case !t.started:
+		return now.Sub(t.created) >= ttl
+	}
+	return false
+}
+
+// close stops the game and drops every client.
+func (t *table) close() {
+	t.cancel()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for c := range t.clients {
+		t.dropLocked(c)
+	}
+}
+
+// run plays the game to the end, or until the table's context is done.
+func (t *table) run() {
+	defer func() {
+		t.mu.Lock()
+		t.ended = time.Now()
+		t.mu.Unlock()
+	}()
+	g := t.g
+	for !g.IsOver() && t.ctx.Err() == nil {
+		if g.CurTurn.RollCnt == 0 {
+			if err := g.EnterRoll(0, g.RandRoll()); err != nil {
+				log.Printf("table %s: %v", t.id, err)
+				return
+			}
+		}
+		cur := g.CurPlayerIdx
+		p := t.seats[cur].player
+		rp, human := p.(*remotePlayer)
+		think := t.botThink
+		if human {
+			think = t.turnTimeout
+		}
+		ctx, cancel := context.WithTimeout(t.ctx, think)
+		t.deadline = time.Time{}
+		if human {
+			t.deadline, _ = ctx.Deadline()
+		}
+		t.publish()
+
+		moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+		moveIdx := p.PickMove(ctx, g, moves)
+		cancel()
+		if moveIdx < 0 || moveIdx >= len(moves) {
+			log.Printf("table %s: player [%s]: %v: index %d of %d", t.id, p, game.ErrUnknownMove, moveIdx, len(moves))
+			return
+		}
+		if _, err := g.Play(moves[moveIdx]); err != nil {
+			log.Printf("table %s: player [%s]: %v", t.id, p, err)
+			return
+		}
+		t.last = &lastMove{Player: cur, Move: moves[moveIdx]}
+		if human {
+			t.last.TimedOut = rp.timedOut
+		}
+		t.held = nil
+	}
+	t.deadline = time.Time{}
+	t.publish()
+}
+
+// publish sends the state of the game to every client. Only run, the
+// players it calls and open call it, since it reads the game.
+func (t *table) publish() {
+	st := stateMessage{
+		Type:     msgState,
+		Game:     t.g.Clone(),
+		Held:     dice.Values(t.held),
+		LastMove: t.last,
+		Over:     t.g.IsOver(),
+	}
+	if !t.deadline.IsZero() {
+		st.Deadline = &t.deadline
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	st.Started = t.started
+	t.state = st
+	t.broadcastLocked()
+}
+
+// broadcastLocked sends the latest state, with the current seats, to
+// every client.
+func (t *table) broadcastLocked() {
+	st := t.state
+	for _, s := range t.seats {
+		st.Seats = append(st.Seats, seatState{Kind: s.kind, Connected: s.client != nil})
+	}
+	data, err := json.Marshal(st)
+	if err != nil {
+		log.Printf("table %s: encoding state: %v", t.id, err)
+		return
+	}
+	for c := range t.clients {
+		t.sendLocked(c, data)
+	}
+}
+
+// sendLocked queues data for c, dropping c if its queue is full.
+func (t *table) sendLocked(c *client, data []byte) {
+	if !t.clients[c] {
+		return
+	}
+	select {
+	case c.send <- data:
+	default:
+		log.Printf("table %s: dropping client that is not keeping up", t.id)
+		t.dropLocked(c)
+	}
+}
+
+func (t *table) sendErrorLocked(c *client, err error) {
+	data, _ := json.Marshal(errorMessage{Type: msgError, Error: err.Error()})
+	t.sendLocked(c, data)
+}
+
+// dropLocked removes c from the table. Its writer closes the connection
+// once it has written what is queued, which ends its reader.
+func (t *table) dropLocked(c *client) {
+	if !t.clients[c] {
+		return
+	}
+	delete(t.clients, c)
+	close(c.send)
+	if c.seat >= 0 && t.seats[c.seat].client == c {
+		t.seats[c.seat].client = nil
+		t.broadcastLocked()
+	}
+}
+
+// join adds c to the table, in seat c.seat with token unless it is a
+// spectator. The seat has already been claimed, but someone else may
+// have taken it since.
+func (t *table) join(c *client, token string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if c.seat >= 0 {
+		s := t.seats[c.seat]
+		if s.token != "" && s.token != token {
+			return errSeatTaken
+		}
+		if old := s.client; old != nil {
+			// The human reconnected before the old connection was
+			// noticed to be gone.
+			s.client = nil
+			t.dropLocked(old)
+		}
+	}
+	t.clients[c] = true
+	if c.seat >= 0 {
+		s := t.seats[c.seat]
+		s.token = token
+		s.client = c
+		data, _ := json.Marshal(welcomeMessage{Type: msgWelcome, Seat: c.seat, Token: token})
+		t.sendLocked(c, data)
+	}
+	t.broadcastLocked()
+	t.startIfReadyLocked()
+	return nil
+}
+
+func (t *table) leave(c *client) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.dropLocked(c)
+}
+
+// serve writes queued messages to c and reads messages from it until the
+// connection ends.
+func (t *table) serve(c *client) {
+	go func() {
+		for data := range c.send {
+			c.ws.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := c.ws.WriteMessage(data); err != nil {
+				break
+			}
+		}
+		c.ws.Close()
+	}()
+	defer t.leave(c)
+
+	for {
+		data, err := c.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg clientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.mu.Lock()
+			t.sendErrorLocked(c, fmt.Errorf("%w: %v", game.ErrInvalidJSON, err))
+			t.mu.Unlock()
+			continue
+		}
+		t.submit(c, msg)
+	}
+}
+
+// submit passes msg to the remote player of c's seat, if it is waiting
+// for a move.
+func (t *table) submit(c *client, msg clientMessage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if c.seat < 0 {
+		t.sendErrorLocked(c, fmt.Errorf("%w: spectators cannot move", game.ErrTurnOrder))
+		return
+	}
+	s := t.seats[c.seat]
+	if !s.waiting || s.client != c {
+		t.sendErrorLocked(c, fmt.Errorf("%w: it is not your turn", game.ErrTurnOrder))
+		return
+	}
+	select {
+	case s.moves <- submission{from: c, msg: msg}:
+	default:
+		t.sendErrorLocked(c, fmt.Errorf("%w: too many moves at once", game.ErrUnknownMove))
+	}
+}
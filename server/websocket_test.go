@@ -0,0 +1,100 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// The example from RFC 6455, section 1.3.
+	if got, want := acceptKey("dGhlIHNhbXBsZSBub25jZQ=="), "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="; got != want {
+		t.Errorf("acceptKey() = %q; want %q", got, want)
+	}
+}
+
+// wsPipe returns the client and server ends of an in-memory connection.
+func wsPipe(t *testing.T) (client, server *wsConn) {
+	a, b := net.Pipe()
+	t.Cleanup(func() {
+		a.Close()
+		b.Close()
+	})
+	return &wsConn{conn: a, br: bufio.NewReader(a), client: true}, &wsConn{conn: b, br: bufio.NewReader(b)}
+}
+
+func TestWebSocketEcho(t *testing.T) {
+	client, server := wsPipe(t)
+	go func() {
+		for {
+			msg, err := server.ReadMessage()
+			if err != nil {
+				server.conn.Close()
+				return
+			}
+			server.WriteMessage(msg)
+		}
+	}()
+
+	// Lengths around each of the three ways a frame encodes its length.
+	for _, n := range []int{0, 5, 125, 126, 300, 0xFFFF, maxMessageBytes} {
+		msg := bytes.Repeat([]byte{'x'}, n)
+		go client.WriteMessage(msg)
+		got, err := client.ReadMessage()
+		if err != nil {
+			t.Fatalf("ReadMessage() of a %d byte message returned unexpected error: %v", n, err)
+		}
+		if !bytes.Equal(got, msg) {
+			t.Errorf("ReadMessage() = %d bytes; want the %d sent", len(got), n)
+		}
+	}
+
+	go client.writeFrame(opPing, []byte("ping"))
+	_, op, payload, err := client.readFrame()
+	if err != nil || op != opPong || string(payload) != "ping" {
+		t.Errorf("reply to ping = (%d, %q, %v); want (%d, %q, nil)", op, payload, err, opPong, "ping")
+	}
+
+	go client.writeFrame(opClose, nil)
+	if _, op, _, err := client.readFrame(); err != nil || op != opClose {
+		t.Errorf("reply to close = (%d, %v); want (%d, nil)", op, err, opClose)
+	}
+}
+
+func TestWebSocketReadErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame []byte
+	}{
+		{"unmasked", []byte{0x81, 0x01, 'x'}},
+		{"reserved bits", []byte{0xC1, 0x81, 0, 0, 0, 0, 'x'}},
+		{"continuation first", []byte{0x80, 0x81, 0, 0, 0, 0, 'x'}},
+		{"fragmented ping", []byte{0x09, 0x80, 0, 0, 0, 0}},
+		{"too big", []byte{0x81, 0xFF, 0, 0, 0, 0, 0, 0x01, 0, 1}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client, server := wsPipe(t)
+			go client.conn.Write(tc.frame)
+			if _, err := server.ReadMessage(); !errors.Is(err, errProtocol) {
+				t.Errorf("ReadMessage() returned error %v; want %v", err, errProtocol)
+			}
+		})
+	}
+
+	client, server := wsPipe(t)
+	go func() {
+		client.conn.Write([]byte{0x01, 0x82, 0, 0, 0, 0, 'a', 'b'})
+		client.conn.Write([]byte{0x80, 0x81, 0, 0, 0, 0, 'c'})
+		client.conn.Close()
+	}()
+	if got, err := server.ReadMessage(); err != nil || string(got) != "abc" {
+		t.Errorf("ReadMessage() of a fragmented message = (%q, %v); want (%q, nil)", got, err, "abc")
+	}
+	if _, err := server.ReadMessage(); err != io.EOF {
+		t.Errorf("ReadMessage() after the peer went away returned error %v; want %v", err, io.EOF)
+	}
+}
@@ -0,0 +1,330 @@
+// Package solver plays the end of a game exactly. Once few turns are left
+// between all the players, it works out the probability of every player
+// winning over every roll of the dice and every choice of every player,
+// each playing to win (expectiminimax, with a value for each player).
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+// DefaultMaxTurns is the most turns left between all players that Default
+// solves. Solving takes well under a second at this size the first time,
+// and around five times as long with every turn more.
+const DefaultMaxTurns = 4
+
+// maxPlayers is the most players a game can have to be solved.
+const maxPlayers = 8
+
+// maxMemo bounds how many positions a solver remembers before it starts
+// over.
+const maxMemo = 1 << 20
+
+var (
+	// ErrTooManyTurns is returned for solving a game with more turns left
+	// than the solver allows, or too many players.
+	ErrTooManyTurns = errors.New("too many turns left to solve")
+)
+
+// Solver solves endgames, remembering the value of every position it
+// has seen so later ones are quicker. It is safe for concurrent use.
+type Solver struct {
+	// MaxTurns is the most turns left between all players for a game to
+	// be solved.
+	MaxTurns int
+
+	// sem is held while solving, which one caller does at a time. It is a
+	// channel rather than a mutex so that waiting for it can be given up
+	// when a caller's ctx is done.
+	sem  chan struct{}
+	memo map[key]value
+}
+
+// Default is the solver players share unless they are given another.
+var Default = New(DefaultMaxTurns)
+
+// New returns a solver for games with at most maxTurns turns left.
+func New(maxTurns int) *Solver {
+	return &Solver{MaxTurns: maxTurns, sem: make(chan struct{}, 1), memo: make(map[key]value)}
+}
+
+// Solvable reports whether g is small enough for s to solve.
+func (s *Solver) Solvable(g *game.Game) bool {
+	if g.IsOver() || len(g.Scorecards) > maxPlayers {
+		return false
+	}
+	turns := 0
+	for _, ps := range g.Scorecards {
+		turns += ps.GetTurnsLeft()
+	}
+	return turns <= s.MaxTurns
+}
+
+// Outcome is how a move turns out for the player making it, when every
+// player plays on to win.
+type Outcome struct {
+	// Win is the probability of not losing: of finishing with at least
+	// as many points as every other player.
+	Win float64
+	// Score is the mean final score.
+	Score float64
+}
+
+// Moves returns the outcome of each of moves for the current player of g,
+// whose dice must be rolled. It returns an error if g is not solvable or
+// ctx is done first, including while waiting for another solve to finish.
+func (s *Solver) Moves(ctx context.Context, g *game.Game, moves []game.Move) ([]Outcome, error) {
+	if !s.Solvable(g) {
+		return nil, fmt.Errorf("%w: at most %d allowed", ErrTooManyTurns, s.MaxTurns)
+	}
+	if g.CurTurn.RollCnt == 0 {
+		return nil, game.ErrNotRolled
+	}
+	select {
+	case s.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-s.sem }()
+	if len(s.memo) > maxMemo {
+		s.memo = make(map[key]value)
+	}
+
+	cur := g.CurPlayerIdx
+	ps := g.Scorecards[cur]
+	p := &position{ctx: ctx, s: s, cards: g.Scorecards}
+	rollsLeft := scoring.MaxReRolls - g.CurTurn.RollCnt
+	var stages *[scoring.MaxReRolls][dice.DistinctRolls]value
+	if rollsLeft > 0 {
+		var err error
+		if stages, err = p.stages(cur); err != nil {
+			return nil, err
+		}
+	}
+
+	outcomes := make([]Outcome, len(moves))
+	for i, m := range moves {
+		var v value
+		if m.Reroll {
+			v = expect(&stages[rollsLeft-1], m.Hold, len(g.Scorecards))
+		} else {
+			var err error
+			if v, err = p.selectValue(cur, g.CurTurn.CurrentRoll, m.Cat); err != nil {
+				return nil, err
+			}
+		}
+		outcomes[i] = Outcome{Win: v.win[cur], Score: float64(ps.RawSum()) + v.gain[cur]}
+	}
+	return outcomes, nil
+}
+
+// Best returns the index of the move in moves most likely to win for the
+// current player of g, or that scores most on average of those as likely.
+func (s *Solver) Best(ctx context.Context, g *game.Game, moves []game.Move) (int, error) {
+	outcomes, err := s.Moves(ctx, g, moves)
+	if err != nil {
+		return 0, err
+	}
+	best := 0
+	for i, o := range outcomes {
+		if o.Better(outcomes[best]) {
+			best = i
+		}
+	}
+	return best, nil
+}
+
+// eps is how close win probabilities have to be to be considered equal.
+const eps = 1e-12
+
+// Better reports whether o is more likely to win than other, or as likely
+// and scores more on average.
+func (o Outcome) Better(other Outcome) bool {
+	if o.Win > other.Win+eps || o.Win < other.Win-eps {
+		return o.Win > other.Win
+	}
+	return o.Score > other.Score
+}
+
+// value is what a position is worth to every player.
+type value struct {
+	// win is the probability of each player not losing.
+	win [maxPlayers]float64
+	// gain is the mean points each player has still to score, including
+	// the upper section bonus.
+	gain [maxPlayers]float64
+}
+
+// betterFor reports whether v is better than o for player p.
+func (v *value) betterFor(o *value, p int) bool {
+	return Outcome{v.win[p], v.gain[p]}.Better(Outcome{o.win[p], o.gain[p]})
+}
+
+// expect returns the value of rerolling the dice not in hold, given the
+// value of every roll.
+func expect(values *[dice.DistinctRolls]value, hold dice.Counts, players int) value {
+	var v value
+	for _, o := range dice.RerollDistribution(hold) {
+		ov := &values[o.Key]
+		for i := range players {
+			v.win[i] += o.P * ov.win[i]
+			v.gain[i] += o.P * ov.gain[i]
+		}
+	}
+	return v
+}
+
+// cardKey is the part of a scorecard its future depends on.
+type cardKey struct {
+	mask uint16
+	// upper is the upper section total, capped at the bonus threshold.
+	upper uint8
+	// yatzy is set once yatzy has been scored, so further yatzies earn a
+	// bonus.
+	yatzy bool
+	// points is the total before the upper section bonus, less the lowest
+	// of every player's.
+	points int16
+}
+
+// key is a position at the start of a turn. Only differences in points
+// matter for winning, and the values stored count points still to come,
+// so positions that differ by the same points for everyone share a key.
+type key struct {
+	cards   [maxPlayers]cardKey
+	players uint8
+	cur     uint8
+}
+
+func newKey(cards []scoring.Scorecard, cur int) key {
+	k := key{players: uint8(len(cards)), cur: uint8(cur)}
+	lowest := cards[0].RawSum()
+	for _, ps := range cards[1:] {
+		lowest = min(lowest, ps.RawSum())
+	}
+	for i, ps := range cards {
+		k.cards[i] = cardKey{
+			mask:   ps.CatMask,
+			upper:  uint8(min(ps.UpperSum(), scoring.UpperSectionMinBonusSum)),
+			yatzy:  ps.ScoresByCategory[scoring.CAT_YATZY] > 0,
+			points: int16(ps.RawSum() - lowest),
+		}
+	}
+	return k
+}
+
+// position solves from the scorecards of a game. s.sem must be held.
+type position struct {
+	ctx   context.Context
+	s     *Solver
+	cards []scoring.Scorecard
+}
+
+// final returns the value of a finished game.
+func (p *position) final() value {
+	var v value
+	var best uint16
+	for _, ps := range p.cards {
+		best = max(best, ps.Score())
+	}
+	for i, ps := range p.cards {
+		if ps.Score() == best {
+			v.win[i] = 1
+		}
+		v.gain[i] = float64(ps.Score() - ps.RawSum())
+	}
+	return v
+}
+
+// start returns the value of the start of cur's turn.
+func (p *position) start(cur int) (value, error) {
+	if p.cards[cur].CatMask == scoring.AllFilled {
+		return p.final(), nil
+	}
+	k := newKey(p.cards, cur)
+	if v, ok := p.s.memo[k]; ok {
+		return v, nil
+	}
+	if err := p.ctx.Err(); err != nil {
+		return value{}, err
+	}
+	stages, err := p.stages(cur)
+	if err != nil {
+		return value{}, err
+	}
+	v := expect(&stages[scoring.MaxReRolls-1], 0, len(p.cards))
+	p.s.memo[k] = v
+	return v, nil
+}
+
+// selectValue returns the value of cur filling c with roll r.
+func (p *position) selectValue(cur int, r dice.Roll, c scoring.Category) (value, error) {
+	ps := p.cards[cur]
+	next := ps.Update(r, c)
+	child := &position{ctx: p.ctx, s: p.s, cards: make([]scoring.Scorecard, len(p.cards))}
+	copy(child.cards, p.cards)
+	child.cards[cur] = next
+	v, err := child.start((cur + 1) % len(p.cards))
+	if err != nil {
+		return value{}, err
+	}
+	v.gain[cur] += float64(next.RawSum() - ps.RawSum())
+	return v, nil
+}
+
+// stages returns the value of every roll in cur's turn with each number
+// of rerolls left, indexed by dice.Roll.Key. Each player picks the move
+// best for them.
+func (p *position) stages(cur int) (*[scoring.MaxReRolls][dice.DistinctRolls]value, error) {
+	players := len(p.cards)
+	ps := p.cards[cur]
+	stages := new([scoring.MaxReRolls][dice.DistinctRolls]value)
+
+	// Selecting only depends on the roll and the category, so the
+	// position after each is solved once for all the rolls.
+	for rk, r := range dice.SortedRolls() {
+		first := true
+		for c := range scoring.Category(scoring.Categories) {
+			if ps.CatMask&(1<<c) != 0 {
+				continue
+			}
+			v, err := p.selectValue(cur, r, c)
+			if err != nil {
+				return nil, err
+			}
+			if first || v.betterFor(&stages[0][rk], cur) {
+				stages[0][rk] = v
+				first = false
+			}
+		}
+	}
+
+	dice.Stages(stages[:], false,
+		func(values *[dice.DistinctRolls]value, hold dice.Counts) value { return expect(values, hold, players) },
+		func(a, b *value) bool { return a.betterFor(b, cur) })
+	return stages, nil
+}
+
+// Player plays as Player until the game can be solved by Solver, and
+// exactly from then on.
+type Player struct {
+	game.Player
+	Solver *Solver
+}
+
+func (p *Player) String() string { return fmt.Sprintf("%s (solving endgames)", p.Player) }
+
+func (p *Player) PickMove(ctx context.Context, g *game.Game, moves []game.Move) int {
+	if p.Solver.Solvable(g) {
+		if best, err := p.Solver.Best(ctx, g, moves); err == nil {
+			return best
+		}
+	}
+	return p.Player.PickMove(ctx, g, moves)
+}
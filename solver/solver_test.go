@@ -0,0 +1,182 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+// lastTurn returns a game where player 1 has only chance left to fill,
+// 30 points behind player 0, who has finished: only a chance of 30 (five
+// sixes) does not lose.
+func lastTurn(t *testing.T, r dice.Roll, rollCnt int) *game.Game {
+	t.Helper()
+	g := game.New(rand.NewPCG(1, 2), make([]game.Player, 2))
+	g.Scorecards[0] = scoring.Scorecard{CatMask: scoring.AllFilled}
+	g.Scorecards[0].ScoresByCategory[scoring.CAT_FULL_HOUSE] = 25
+	g.Scorecards[0].ScoresByCategory[scoring.CAT_LARGE_STRAIGHT] = 40
+	g.Scorecards[0].ScoresByCategory[scoring.CAT_CHANCE] = 35
+	g.Scorecards[1] = scoring.Scorecard{CatMask: scoring.AllFilled &^ (1 << scoring.CAT_CHANCE)}
+	g.Scorecards[1].ScoresByCategory[scoring.CAT_SMALL_STRAIGHT] = 30
+	g.Scorecards[1].ScoresByCategory[scoring.CAT_LARGE_STRAIGHT] = 40
+	g.CurPlayerIdx = 1
+	g.CurTurn = &game.Turn{CurrentRoll: r, RollCnt: rollCnt}
+	return g
+}
+
+func TestPlaysToWin(t *testing.T) {
+	g := lastTurn(t, dice.NewRoll(6, 6, 6, 5, 1), 1)
+	moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+	s := New(DefaultMaxTurns)
+	outcomes, err := s.Moves(context.Background(), g, moves)
+	if err != nil {
+		t.Fatalf("Moves() returned unexpected error: %v", err)
+	}
+	best, err := s.Best(context.Background(), g, moves)
+	if err != nil {
+		t.Fatalf("Best() returned unexpected error: %v", err)
+	}
+
+	// Keeping the sixes and rerolling the rest twice, each die comes up
+	// six with probability 1 - (5/6)^2.
+	six := 1 - 25.0/36
+	if want := game.NewRerollMove(6, 6, 6); moves[best] != want {
+		t.Errorf("Best() picked %s; want %s", moves[best], want)
+	}
+	if got, want := outcomes[best].Win, six*six; math.Abs(got-want) > 1e-12 {
+		t.Errorf("%s wins with probability %v; want %v", moves[best], got, want)
+	}
+
+	// Selecting now can never win.
+	for i, m := range moves {
+		if !m.Reroll && outcomes[i] != (Outcome{Win: 0, Score: 94}) {
+			t.Errorf("%s has outcome %+v; want 94 points and no wins", m, outcomes[i])
+		}
+	}
+}
+
+func TestLastRoll(t *testing.T) {
+	for _, tt := range []struct {
+		roll dice.Roll
+		want Outcome
+	}{
+		{dice.NewRoll(6, 6, 6, 6, 6), Outcome{Win: 1, Score: 100}},
+		{dice.NewRoll(6, 6, 6, 6, 5), Outcome{Win: 0, Score: 99}},
+	} {
+		g := lastTurn(t, tt.roll, scoring.MaxReRolls)
+		moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+		got, err := New(1).Moves(context.Background(), g, moves)
+		if err != nil {
+			t.Fatalf("Moves() returned unexpected error: %v", err)
+		}
+		if len(got) != 1 || got[0] != tt.want {
+			t.Errorf("Moves() for %s = %+v; want [%+v]", tt.roll, got, tt.want)
+		}
+	}
+}
+
+func TestSolvable(t *testing.T) {
+	g := game.New(rand.NewPCG(1, 2), make([]game.Player, 2))
+	s := New(DefaultMaxTurns)
+	if s.Solvable(g) {
+		t.Errorf("Solvable() = true for a new game")
+	}
+	if err := g.EnterRoll(0, dice.NewRoll(1, 2, 3, 4, 5)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Moves(context.Background(), g, nil); !errors.Is(err, ErrTooManyTurns) {
+		t.Errorf("Moves() for a new game returned error %v; want %v", err, ErrTooManyTurns)
+	}
+
+	g = lastTurn(t, dice.NewRoll(1, 2, 3, 4, 5), 0)
+	if !s.Solvable(g) {
+		t.Errorf("Solvable() = false with one turn left")
+	}
+	if _, err := s.Moves(context.Background(), g, nil); !errors.Is(err, game.ErrNotRolled) {
+		t.Errorf("Moves() before rolling returned error %v; want %v", err, game.ErrNotRolled)
+	}
+}
+
+// TestWinProbabilitiesSum checks that with two turns each left, every
+// outcome has a winner: the chances of each player not losing add up to 1
+// plus the chance of a tie.
+func TestWinProbabilitiesSum(t *testing.T) {
+	g := game.New(rand.NewPCG(1, 2), make([]game.Player, 2))
+	for i := range g.Scorecards {
+		g.Scorecards[i].CatMask = scoring.AllFilled &^ (1<<scoring.CAT_SIXES | 1<<scoring.CAT_FULL_HOUSE)
+	}
+	g.Scorecards[1].ScoresByCategory[scoring.CAT_CHANCE] = 10
+	s := New(DefaultMaxTurns)
+	p := &position{ctx: context.Background(), s: s, cards: g.Scorecards}
+	v, err := p.start(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total := v.win[0] + v.win[1]; total < 1-1e-9 || total > 1.1 {
+		t.Errorf("chances of not losing add up to %v; want a little over 1", total)
+	}
+	if len(s.memo) == 0 {
+		t.Errorf("no positions remembered after solving")
+	}
+
+	// Solving again is answered from memory.
+	before := len(s.memo)
+	again, err := p.start(0)
+	if err != nil || again != v || len(s.memo) != before {
+		t.Errorf("solving again returned %+v, %v with %d positions remembered; want %+v with %d", again, err, len(s.memo), v, before)
+	}
+}
+
+// firstPlayer picks the first move and records that it was asked.
+type firstPlayer struct {
+	asked bool
+}
+
+func (fp *firstPlayer) PickMove(context.Context, *game.Game, []game.Move) int {
+	fp.asked = true
+	return 0
+}
+
+func TestPlayer(t *testing.T) {
+	fallback := &firstPlayer{}
+	p := &Player{Player: fallback, Solver: New(DefaultMaxTurns)}
+
+	g := lastTurn(t, dice.NewRoll(6, 6, 6, 5, 1), 1)
+	moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+	if got, want := moves[p.PickMove(context.Background(), g, moves)], game.NewRerollMove(6, 6, 6); got != want || fallback.asked {
+		t.Errorf("PickMove() in the endgame picked %s (asked fallback: %t); want %s without asking", got, fallback.asked, want)
+	}
+
+	g = game.New(rand.NewPCG(1, 2), make([]game.Player, 2))
+	if err := g.EnterRoll(0, dice.NewRoll(6, 6, 6, 5, 1)); err != nil {
+		t.Fatal(err)
+	}
+	moves = g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+	if p.PickMove(context.Background(), g, moves); !fallback.asked {
+		t.Errorf("PickMove() at the start of the game did not ask the fallback player")
+	}
+}
+
+func TestMovesGivesUpWaiting(t *testing.T) {
+	s := New(DefaultMaxTurns)
+	s.sem <- struct{}{} // another solve is running.
+	g := lastTurn(t, dice.NewRoll(6, 6, 6, 5, 1), 1)
+	moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.Moves(ctx, g, moves); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Moves() while another solve runs returned %v; want %v", err, context.DeadlineExceeded)
+	}
+
+	<-s.sem
+	if _, err := s.Moves(context.Background(), g, moves); err != nil {
+		t.Errorf("Moves() once the other solve finished returned unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,334 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/solver"
+)
+
+// MonteCarloPlayer picks the move that does best in random playouts.
+type MonteCarloPlayer struct {
+	Rng *rand.Rand
+	// Endgame, if set, solves the game exactly instead once it can.
+	Endgame *solver.Solver
+	// Objective is what moves are ranked by.
+	Objective Objective
+	// Racing drops clearly worse moves early, to play out the rest more.
+	Racing Racing
+	// Out, if set, is where PickMove writes how every move did. If nil,
+	// PickMove writes nothing.
+	Out io.Writer
+}
+
+func (mcp *MonteCarloPlayer) String() string { return "MC" }
+
+type result struct {
+	moveIdx int
+	score   uint16
+	won     bool
+}
+
+// MoveStats are the results of the playouts that started with one move.
+// The fields ending in Err are the standard errors of the statistics
+// before them, left 0 with fewer than two playouts.
+type MoveStats struct {
+	// Index is the index of Move in the moves that were ranked.
+	Index int       `json:"-"`
+	Move  game.Move `json:"move"`
+	// Games is the number of playouts that started with Move.
+	Games uint64 `json:"games"`
+	// Mean is the mean final score of the player to move.
+	Mean    float64 `json:"mean"`
+	MeanErr float64 `json:"meanErr"`
+	// StdDev is the standard deviation of the final scores.
+	StdDev float64 `json:"stdDev"`
+	// Max is the best final score of the player to move.
+	Max uint16 `json:"max"`
+	// TopMean is the mean of the best Objective.TopN final scores.
+	TopMean    float64 `json:"topMean"`
+	TopMeanErr float64 `json:"topMeanErr"`
+	// WinRate is the fraction of playouts the player to move did not lose.
+	WinRate    float64 `json:"winRate"`
+	WinRateErr float64 `json:"winRateErr"`
+	// Quantile is the final score at Objective.Quantile.
+	Quantile    float64 `json:"quantile"`
+	QuantileErr float64 `json:"quantileErr"`
+	// RiskAdjusted is Mean less Objective.RiskAversion times StdDev.
+	RiskAdjusted    float64 `json:"riskAdjusted"`
+	RiskAdjustedErr float64 `json:"riskAdjustedErr"`
+	// Value is the statistic the moves were ranked by.
+	Value    float64 `json:"value"`
+	ValueErr float64 `json:"valueErr"`
+	// Eliminated is why the move was dropped from the playouts before the
+	// end, if it was (see Racing).
+	Eliminated string `json:"eliminated,omitempty"`
+	// Exact is set when the endgame was solved rather than played out:
+	// Mean and WinRate are then exact, for every player playing to win,
+	// and there are no playouts.
+	Exact bool `json:"exact,omitempty"`
+	// Unranked is set when no playout finished in time, so the moves are
+	// only ordered by what they score now (see Rank).
+	Unranked bool `json:"unranked,omitempty"`
+}
+
+func (ms MoveStats) String() string {
+	if ms.Unranked {
+		return fmt.Sprintf("%s (unranked)", ms.Move)
+	}
+	if ms.Exact {
+		return fmt.Sprintf("%s (exact) (%.4f avg) (%.2f won pct)", ms.Move, ms.Mean, ms.WinRate)
+	}
+	s := fmt.Sprintf("%s (%d games) (%.2f±%.2f avg) (%.2f sd) (%d max) (%.2f±%.2f top n avg) (%.2f±%.2f won pct) (%.0f±%.1f quantile) (%.2f±%.2f risk adjusted)",
+		ms.Move, ms.Games, ms.Mean, ms.MeanErr, ms.StdDev, ms.Max, ms.TopMean, ms.TopMeanErr, ms.WinRate, ms.WinRateErr,
+		ms.Quantile, ms.QuantileErr, ms.RiskAdjusted, ms.RiskAdjustedErr)
+	if ms.Eliminated != "" {
+		s += " (" + ms.Eliminated + ")"
+	}
+	return s
+}
+
+// PickMove plays out random games from every move until ctx is done,
+// writes how each move did to Out and picks the best.
+func (mcp *MonteCarloPlayer) PickMove(ctx context.Context, g *game.Game, moves []game.Move) int {
+	start := time.Now()
+	ranked := mcp.Rank(ctx, g, moves)
+	if mcp.Out != nil {
+		mcp.report(ranked, time.Since(start))
+	}
+	return ranked[0].Index
+}
+
+// report writes ranked, the stats of the moves ranked in took, to Out.
+func (mcp *MonteCarloPlayer) report(ranked []MoveStats, took time.Duration) {
+	w := mcp.Out
+	if ranked[0].Unranked {
+		fmt.Fprintf(w, "No playouts finished in %s, so picked the best scoring move.\n", took)
+		return
+	}
+	if ranked[0].Exact {
+		fmt.Fprintf(w, "Solved the endgame in %s\n", took)
+		for i, ms := range ranked {
+			fmt.Fprintf(w, "[%d]: %s\n", i, ms)
+		}
+		return
+	}
+
+	var totalGamesExplored uint64
+	dropped := 0
+	for _, ms := range ranked {
+		totalGamesExplored += ms.Games
+		if ms.Eliminated != "" {
+			dropped++
+		}
+	}
+	fmt.Fprintf(w, "Stopped. Explored %d games with %d workers (%.2f g/s)\n", totalGamesExplored, workers, float64(totalGamesExplored)/took.Seconds())
+	if dropped > 0 {
+		fmt.Fprintf(w, "Dropped %d of %d moves early.\n", dropped, len(ranked))
+	}
+	fmt.Fprintf(w, "Ranked by %s", mcp.Objective)
+	if len(ranked) > 1 {
+		first, second := ranked[0], ranked[1]
+		if se := math.Hypot(first.ValueErr, second.ValueErr); se > 0 {
+			fmt.Fprintf(w, ", the best %.1f standard errors ahead of the next", (first.Value-second.Value)/se)
+		}
+	}
+	fmt.Fprintln(w)
+	for i, ms := range ranked {
+		fmt.Fprintf(w, "[%d]: %s\n", i, ms)
+	}
+}
+
+// workers is the number of goroutines playing out games.
+const workers = 100
+
+// endgameShare is the share of the time left that solving the endgame may
+// take, so that if it cannot finish in time, such as while another table
+// is solving, the moves can still be played out.
+const endgameShare = 0.5
+
+// Rank plays out random games from every move until ctx is done and
+// returns the stats of every move, best first. Moves dropped by racing
+// rank after the rest. If the endgame can be solved, it ranks the moves
+// exactly instead, most likely to win first, unless solving takes more
+// than endgameShare of the time. If no playout finishes before ctx is
+// done, the moves are marked Unranked and ordered by unrankedOrder.
+func (mcp *MonteCarloPlayer) Rank(ctx context.Context, g *game.Game, moves []game.Move) []MoveStats {
+	if mcp.Endgame != nil && mcp.Endgame.Solvable(g) {
+		if outcomes, err := mcp.solveEndgame(ctx, g, moves); err == nil {
+			return rankExactly(moves, outcomes)
+		}
+	}
+	var wg sync.WaitGroup
+	results := make(chan result)
+
+	// Workers play out the moves still in the race, all of them unless
+	// racing drops some.
+	var active atomic.Pointer[[]int]
+	all := make([]int, len(moves))
+	for i := range all {
+		all[i] = i
+	}
+	active.Store(&all)
+
+	playerIdx := g.CurPlayerIdx
+	for i := 0; i < workers; i++ {
+		// A rand.Rand is not safe for concurrent use, so every worker
+		// rolls its own.
+		rng := rand.New(rand.NewPCG(mcp.Rng.Uint64(), mcp.Rng.Uint64()))
+		wg.Add(1)
+		go func(ctx context.Context) {
+			defer wg.Done()
+			for {
+				left := *active.Load()
+				moveIdx := left[rng.IntN(len(left))]
+
+				sg := g.Clone()
+				sg.Rng = rng
+				sg.Players = make([]game.Player, len(sg.Scorecards))
+				for i := range sg.Players {
+					sg.Players[i] = &RandomPlayer{Rng: rng}
+				}
+				if !sg.DoMove(moves[moveIdx]) {
+					sg.RunSimulation(ctx)
+				}
+				if !sg.IsOver() {
+					return // cut short by ctx, so it must not be counted.
+				}
+
+				selfScore := sg.Scorecards[playerIdx].Score()
+				var opponentScore uint16
+				for i, ps := range sg.Scorecards {
+					if i != playerIdx {
+						opponentScore = max(opponentScore, ps.Score())
+					}
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case results <- result{
+					moveIdx: moveIdx,
+					score:   selfScore,
+					won:     selfScore >= opponentScore, // anything that isn't a loss is a win :)
+				}:
+				}
+			}
+		}(ctx)
+	}
+
+	statsByMove := make([]playouts, len(moves))
+	ranked := make([]MoveStats, len(moves))
+	sinceRace := 0
+
+think:
+	for {
+		select {
+		case <-ctx.Done():
+			break think
+		case r := <-results:
+			if ranked[r.moveIdx].Eliminated != "" {
+				continue // played out before it was dropped.
+			}
+			statsByMove[r.moveIdx].add(r)
+			if sinceRace++; mcp.Racing.Z > 0 && sinceRace >= raceEvery {
+				sinceRace = 0
+				left := *active.Load()
+				if next := mcp.Racing.race(mcp.Objective, moves, statsByMove, ranked, left); len(next) < len(left) {
+					active.Store(&next)
+				}
+			}
+		}
+	}
+
+	for moveIdx := range statsByMove {
+		ms := &ranked[moveIdx]
+		if ms.Eliminated != "" {
+			continue
+		}
+		ms.Index, ms.Move = moveIdx, moves[moveIdx]
+		statsByMove[moveIdx].stats(mcp.Objective, ms)
+		ms.Value, ms.ValueErr = mcp.Objective.value(ms)
+	}
+
+	var played uint64
+	for _, ms := range ranked {
+		played += ms.Games
+	}
+	if played == 0 {
+		wg.Wait()
+		return unrankedOrder(moves)
+	}
+
+	// Ties, such as every move winning in a game alone, go to the best
+	// mean.
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if dropped := ranked[i].Eliminated != ""; dropped != (ranked[j].Eliminated != "") {
+			return !dropped
+		}
+		if ranked[i].Value != ranked[j].Value {
+			return ranked[i].Value > ranked[j].Value
+		}
+		return ranked[i].Mean > ranked[j].Mean
+	})
+
+	wg.Wait() // Wait for threads.
+	return ranked
+}
+
+// solveEndgame solves the outcome of every move, giving up after
+// endgameShare of the time left before ctx is done.
+func (mcp *MonteCarloPlayer) solveEndgame(ctx context.Context, g *game.Game, moves []game.Move) ([]solver.Outcome, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		budget := time.Duration(float64(time.Until(deadline)) * endgameShare)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+	return mcp.Endgame.Moves(ctx, g, moves)
+}
+
+// unrankedOrder returns moves, with nothing to rank them by, ordered by
+// the points selecting scores now, highest first, and then the rerolls.
+// That never throws away a good roll the way the first open category
+// might.
+func unrankedOrder(moves []game.Move) []MoveStats {
+	ranked := make([]MoveStats, len(moves))
+	for i, m := range moves {
+		ranked[i] = MoveStats{Index: i, Move: m, Unranked: true}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i].Move, ranked[j].Move
+		if a.Reroll != b.Reroll {
+			return !a.Reroll
+		}
+		return a.Score > b.Score
+	})
+	return ranked
+}
+
+// rankExactly returns the stats of moves with the outcomes of solving the
+// endgame, most likely to win first, whatever the objective.
+func rankExactly(moves []game.Move, outcomes []solver.Outcome) []MoveStats {
+	ranked := make([]MoveStats, len(moves))
+	for i, o := range outcomes {
+		ranked[i] = MoveStats{
+			Index:   i,
+			Move:    moves[i],
+			Mean:    o.Score,
+			WinRate: o.Win,
+			Exact:   true,
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return outcomes[ranked[i].Index].Better(outcomes[ranked[j].Index])
+	})
+	return ranked
+}
@@ -0,0 +1,58 @@
+package strategy
+
+import (
+	"context"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/scoring"
+	"github.com/AustinJGreen/goyatzy/solver"
+)
+
+func TestRankWithoutTime(t *testing.T) {
+	g := game.New(rand.NewPCG(1, 2), make([]game.Player, 1))
+	if err := g.EnterRoll(0, dice.NewRoll(6, 6, 6, 6, 6)); err != nil {
+		t.Fatal(err)
+	}
+	moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mcp := &MonteCarloPlayer{Rng: rand.New(rand.NewPCG(3, 4))}
+	ranked := mcp.Rank(ctx, g, moves)
+	for _, ms := range ranked {
+		if !ms.Unranked || ms.Games != 0 {
+			t.Fatalf("Rank() with no time ranked %s; want it unranked", ms)
+		}
+	}
+	if got, want := ranked[0].Move, g.SelectMove(scoring.CAT_YATZY); got != want {
+		t.Errorf("Rank() with no time put %s first; want %s", got, want)
+	}
+}
+
+// TestRankUnsolvedEndgame checks that an endgame the solver cannot solve
+// in its share of the time is still played out.
+func TestRankUnsolvedEndgame(t *testing.T) {
+	g := game.New(rand.NewPCG(1, 2), make([]game.Player, 1))
+	g.Scorecards[0].CatMask = 1<<scoring.CAT_ONES | 1<<scoring.CAT_TWOS | 1<<scoring.CAT_THREES | 1<<scoring.CAT_FOURS | 1<<scoring.CAT_FIVES
+	if err := g.EnterRoll(0, dice.NewRoll(6, 6, 6, 5, 1)); err != nil {
+		t.Fatal(err)
+	}
+	moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+
+	// Eight turns take the solver far longer than it is given.
+	mcp := &MonteCarloPlayer{Rng: rand.New(rand.NewPCG(3, 4)), Endgame: solver.New(8)}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	ranked := mcp.Rank(ctx, g, moves)
+	var games uint64
+	for _, ms := range ranked {
+		games += ms.Games
+	}
+	if ranked[0].Exact || ranked[0].Unranked || games == 0 {
+		t.Errorf("Rank() of an endgame too big to solve in time returned %s after %d playouts; want moves ranked by playouts", ranked[0], games)
+	}
+}
@@ -0,0 +1,279 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Statistic is a statistic of the final scores of playouts that moves can
+// be ranked by.
+type Statistic int
+
+const (
+	// ByTopMean ranks by the mean of the best scores, rewarding moves
+	// that can score big.
+	ByTopMean Statistic = iota
+	// ByMean ranks by the mean score.
+	ByMean
+	// ByWinRate ranks by the fraction of playouts not lost.
+	ByWinRate
+	// ByQuantile ranks by a quantile of the scores, such as the median.
+	ByQuantile
+	// ByRiskAdjusted ranks by the mean score less a multiple of the
+	// standard deviation, penalizing moves that swing either way.
+	ByRiskAdjusted
+)
+
+var statisticNames = map[Statistic]string{
+	ByTopMean:      "top",
+	ByMean:         "mean",
+	ByWinRate:      "win",
+	ByQuantile:     "quantile",
+	ByRiskAdjusted: "risk",
+}
+
+// Defaults for the parameters of an Objective left zero.
+const (
+	DefaultTopN         = 50
+	DefaultQuantile     = 0.5
+	DefaultRiskAversion = 1.0
+)
+
+// ErrInvalidObjective is returned for parsing an objective that is not
+// one.
+var ErrInvalidObjective = errors.New("invalid objective")
+
+// Objective is what MonteCarloPlayer ranks moves by. The zero Objective
+// ranks by the mean of the best DefaultTopN scores.
+type Objective struct {
+	By Statistic
+	// TopN is how many of the best scores TopMean averages.
+	TopN int
+	// Quantile is the quantile of the scores reported, in (0, 1).
+	Quantile float64
+	// RiskAversion is k in the risk adjusted mean, mean - k·stddev.
+	RiskAversion float64
+}
+
+func (o Objective) topN() int {
+	if o.TopN <= 0 {
+		return DefaultTopN
+	}
+	return o.TopN
+}
+
+func (o Objective) quantile() float64 {
+	if o.Quantile <= 0 || o.Quantile >= 1 {
+		return DefaultQuantile
+	}
+	return o.Quantile
+}
+
+func (o Objective) riskAversion() float64 {
+	if o.RiskAversion == 0 {
+		return DefaultRiskAversion
+	}
+	return o.RiskAversion
+}
+
+// String returns o as ParseObjective reads it, such as "top:50", "mean",
+// "win", "quantile:0.25" or "risk:1".
+func (o Objective) String() string {
+	name := statisticNames[o.By]
+	switch o.By {
+	case ByTopMean:
+		return fmt.Sprintf("%s:%d", name, o.topN())
+	case ByQuantile:
+		return fmt.Sprintf("%s:%g", name, o.quantile())
+	case ByRiskAdjusted:
+		return fmt.Sprintf("%s:%g", name, o.riskAversion())
+	}
+	return name
+}
+
+// ParseObjective parses an objective written as the statistic to rank by,
+// optionally followed by a colon and its parameter: "top:50" for the mean
+// of the best 50 scores, "mean", "win", "quantile:0.25" or "risk:1" for
+// the mean less one standard deviation.
+func ParseObjective(s string) (Objective, error) {
+	name, param, hasParam := strings.Cut(s, ":")
+	var o Objective
+	found := false
+	for by, n := range statisticNames {
+		if n == name {
+			o.By, found = by, true
+		}
+	}
+	if !found {
+		names := slices.Sorted(maps.Values(statisticNames))
+		return Objective{}, fmt.Errorf("%w: %q (want one of %s)", ErrInvalidObjective, s, strings.Join(names, ", "))
+	}
+	if !hasParam {
+		return o, nil
+	}
+
+	var err error
+	switch o.By {
+	case ByTopMean:
+		o.TopN, err = strconv.Atoi(param)
+		if err == nil && o.TopN <= 0 {
+			err = errors.New("must be positive")
+		}
+	case ByQuantile:
+		o.Quantile, err = strconv.ParseFloat(param, 64)
+		if err == nil && (o.Quantile <= 0 || o.Quantile >= 1) {
+			err = errors.New("must be between 0 and 1")
+		}
+	case ByRiskAdjusted:
+		o.RiskAversion, err = strconv.ParseFloat(param, 64)
+		if err == nil && (o.RiskAversion <= 0 || math.IsInf(o.RiskAversion, 0)) {
+			err = errors.New("must be positive")
+		}
+	default:
+		err = errors.New("takes no parameter")
+	}
+	if err != nil {
+		return Objective{}, fmt.Errorf("%w: %q: %w", ErrInvalidObjective, s, err)
+	}
+	return o, nil
+}
+
+func (o Objective) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+func (o *Objective) UnmarshalText(text []byte) error {
+	parsed, err := ParseObjective(string(text))
+	if err != nil {
+		return err
+	}
+	*o = parsed
+	return nil
+}
+
+// value returns the statistic of ms o ranks by and its standard error.
+func (o Objective) value(ms *MoveStats) (float64, float64) {
+	switch o.By {
+	case ByMean:
+		return ms.Mean, ms.MeanErr
+	case ByWinRate:
+		return ms.WinRate, ms.WinRateErr
+	case ByQuantile:
+		return ms.Quantile, ms.QuantileErr
+	case ByRiskAdjusted:
+		return ms.RiskAdjusted, ms.RiskAdjustedErr
+	}
+	return ms.TopMean, ms.TopMeanErr
+}
+
+// playouts tallies the final scores of the playouts that started with a
+// move.
+type playouts struct {
+	counts map[uint16]uint64
+	games  uint64
+	wins   uint64
+}
+
+func (p *playouts) add(r result) {
+	if p.counts == nil {
+		p.counts = make(map[uint16]uint64)
+	}
+	p.counts[r.score]++
+	p.games++
+	if r.won {
+		p.wins++
+	}
+}
+
+// stats fills in the statistics of ms from the playouts, with standard
+// errors: from the variance of the mean, the binomial variance of the win
+// rate, the asymptotic variance of the mean of the upper tail for the top
+// mean, the spread of the scores at the quantiles a standard error either
+// side of the quantile, and the delta method for the risk adjusted mean.
+// Standard errors are left 0 with fewer than two playouts.
+func (p *playouts) stats(o Objective, ms *MoveStats) {
+	ms.Games = p.games
+	if p.games == 0 {
+		return
+	}
+	n := float64(p.games)
+	scores := slices.Sorted(maps.Keys(p.counts))
+	ms.Max = scores[len(scores)-1]
+
+	var sum float64
+	for _, s := range scores {
+		sum += float64(s) * float64(p.counts[s])
+	}
+	mean := sum / n
+	var m2, m3, m4 float64
+	for _, s := range scores {
+		d := float64(s) - mean
+		c := float64(p.counts[s]) / n
+		m2 += c * d * d
+		m3 += c * d * d * d
+		m4 += c * d * d * d * d
+	}
+	ms.Mean = mean
+	ms.WinRate = float64(p.wins) / n
+
+	// The mean of the best scores, and the lowest of them.
+	top := min(uint64(o.topN()), p.games)
+	var topSum, topSq float64
+	var cutoff uint16
+	left := top
+	for i := len(scores) - 1; left > 0; i-- {
+		s := scores[i]
+		c := float64(min(p.counts[s], left))
+		left -= uint64(c)
+		topSum += c * float64(s)
+		topSq += c * float64(s) * float64(s)
+		cutoff = s
+	}
+	ms.TopMean = topSum / float64(top)
+	ms.Quantile = float64(p.quantile(scores, o.quantile()))
+	k := o.riskAversion()
+	ms.StdDev = math.Sqrt(m2 * n / max(n-1, 1))
+	ms.RiskAdjusted = mean - k*ms.StdDev
+
+	if p.games < 2 {
+		return
+	}
+	ms.MeanErr = ms.StdDev / math.Sqrt(n)
+	ms.WinRateErr = math.Sqrt(ms.WinRate * (1 - ms.WinRate) / n)
+
+	tailFrac := float64(top) / n
+	tailVar := max(topSq/float64(top)-ms.TopMean*ms.TopMean, 0)
+	gap := ms.TopMean - float64(cutoff)
+	ms.TopMeanErr = math.Sqrt((tailVar + (1-tailFrac)*gap*gap) / float64(top))
+
+	q := o.quantile()
+	spread := math.Sqrt(q * (1 - q) / n)
+	lo := p.quantile(scores, max(q-spread, 0))
+	hi := p.quantile(scores, min(q+spread, 1))
+	ms.QuantileErr = float64(hi-lo) / 2
+
+	if m2 > 0 {
+		sd := math.Sqrt(m2)
+		v := m2 - k*m3/sd + k*k*(m4-m2*m2)/(4*m2)
+		ms.RiskAdjustedErr = math.Sqrt(max(v, 0) / n)
+	}
+}
+
+// quantile returns the lowest of scores, the distinct scores played
+// lowest first, that at least q of the playouts scored at most.
+func (p *playouts) quantile(scores []uint16, q float64) uint16 {
+	want := q * float64(p.games)
+	var seen float64
+	for _, s := range scores {
+		seen += float64(p.counts[s])
+		if seen >= want-1e-9 {
+			return s
+		}
+	}
+	return scores[len(scores)-1]
+}
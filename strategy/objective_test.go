@@ -0,0 +1,74 @@
+package strategy
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestParseObjective(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want Objective
+		str  string
+	}{
+		{"top", Objective{By: ByTopMean}, "top:50"},
+		{"top:10", Objective{By: ByTopMean, TopN: 10}, "top:10"},
+		{"mean", Objective{By: ByMean}, "mean"},
+		{"win", Objective{By: ByWinRate}, "win"},
+		{"quantile:0.25", Objective{By: ByQuantile, Quantile: 0.25}, "quantile:0.25"},
+		{"risk:2", Objective{By: ByRiskAdjusted, RiskAversion: 2}, "risk:2"},
+	} {
+		got, err := ParseObjective(tt.s)
+		if err != nil {
+			t.Errorf("ParseObjective(%q) returned unexpected error: %v", tt.s, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseObjective(%q) = %+v; want %+v", tt.s, got, tt.want)
+		}
+		if got.String() != tt.str {
+			t.Errorf("ParseObjective(%q).String() = %q; want %q", tt.s, got.String(), tt.str)
+		}
+	}
+
+	for _, s := range []string{"", "max", "top:0", "top:x", "quantile:1", "risk:-1", "mean:2"} {
+		if _, err := ParseObjective(s); !errors.Is(err, ErrInvalidObjective) {
+			t.Errorf("ParseObjective(%q) returned error %v; want %v", s, err, ErrInvalidObjective)
+		}
+	}
+}
+
+func TestPlayoutStats(t *testing.T) {
+	var p playouts
+	for _, r := range []result{{score: 100}, {score: 200, won: true}, {score: 300, won: true}, {score: 300, won: true}} {
+		p.add(r)
+	}
+	var got MoveStats
+	p.stats(Objective{TopN: 2}, &got)
+
+	sd := math.Sqrt((125*125 + 25*25 + 2*75*75) / 3.0)
+	want := MoveStats{
+		Games:        4,
+		Mean:         225,
+		MeanErr:      sd / 2,
+		StdDev:       sd,
+		Max:          300,
+		TopMean:      300,
+		WinRate:      0.75,
+		WinRateErr:   math.Sqrt(0.75 * 0.25 / 4),
+		Quantile:     200,
+		QuantileErr:  100, // the quartiles either side, 100 and 300.
+		RiskAdjusted: 225 - sd,
+	}
+	opts := []cmp.Option{cmpopts.EquateApprox(0, 1e-9), cmpopts.IgnoreFields(MoveStats{}, "RiskAdjustedErr")}
+	if diff := cmp.Diff(got, want, opts...); diff != "" {
+		t.Errorf("stats() mismatch (-got, +want):\n%s", diff)
+	}
+	if got.RiskAdjustedErr <= 0 {
+		t.Errorf("RiskAdjustedErr = %v; want more than 0", got.RiskAdjustedErr)
+	}
+}
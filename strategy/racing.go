@@ -0,0 +1,75 @@
+package strategy
+
+import (
+	"fmt"
+
+	"github.com/AustinJGreen/goyatzy/game"
+)
+
+// DefaultRacing is the racing the built-in bots play with.
+var DefaultRacing = Racing{Z: 3, MinGames: DefaultRaceMinGames}
+
+// DefaultRaceMinGames is how many playouts a move gets before it can be
+// dropped, if Racing.MinGames is 0.
+const DefaultRaceMinGames = 200
+
+// raceEvery is how many playouts are played between looking for moves to
+// drop.
+const raceEvery = 1000
+
+// Racing drops moves from the playouts once they are clearly worse than
+// another, so the rest get more of them: a move is dropped once its
+// objective, even Z standard errors higher, is below that of the move
+// with the best objective Z standard errors lower. The moves still in the
+// race are played out about as often as each other, so statistics that
+// depend on the number of playouts, like the top mean, compare fairly.
+type Racing struct {
+	// Z is how many standard errors apart moves must be to drop the worse.
+	// With Z 0, every move is played out to the end.
+	Z float64
+	// MinGames is how many playouts a move gets before it can be dropped
+	// or drop another.
+	MinGames uint64
+}
+
+func (r Racing) minGames() uint64 {
+	if r.MinGames == 0 {
+		return DefaultRaceMinGames
+	}
+	return r.MinGames
+}
+
+// race drops the moves in active clearly worse than the best of them,
+// filling in their stats in ranked with why they were dropped, and
+// returns the moves left.
+func (r Racing) race(o Objective, moves []game.Move, stats []playouts, ranked []MoveStats, active []int) []int {
+	leader, bound := -1, 0.0
+	for _, i := range active {
+		if stats[i].games < r.minGames() {
+			continue
+		}
+		ms := &ranked[i]
+		ms.Index, ms.Move = i, moves[i]
+		stats[i].stats(o, ms)
+		ms.Value, ms.ValueErr = o.value(ms)
+		if lower := ms.Value - r.Z*ms.ValueErr; leader < 0 || lower > bound {
+			leader, bound = i, lower
+		}
+	}
+	if leader < 0 {
+		return active
+	}
+
+	var left []int
+	lead := &ranked[leader]
+	for _, i := range active {
+		ms := &ranked[i]
+		if stats[i].games < r.minGames() || ms.Value+r.Z*ms.ValueErr >= bound {
+			left = append(left, i)
+			continue
+		}
+		ms.Eliminated = fmt.Sprintf("dropped after %d playouts: %s %.2f±%.2f cannot reach the %.2f±%.2f of %s within %g standard errors",
+			ms.Games, o, ms.Value, ms.ValueErr, lead.Value, lead.ValueErr, lead.Move, r.Z)
+	}
+	return left
+}
@@ -0,0 +1,50 @@
+package strategy
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+func TestRace(t *testing.T) {
+	moves := []game.Move{
+		{Cat: scoring.CAT_ONES},
+		{Cat: scoring.CAT_TWOS},
+		{Cat: scoring.CAT_THREES},
+		{Cat: scoring.CAT_FOURS},
+	}
+	stats := make([]playouts, len(moves))
+	for i := range 100 {
+		// The first two moves are close, the third far behind and the
+		// last too little played to judge.
+		stats[0].add(result{score: uint16(200 + i%20)})
+		stats[1].add(result{score: uint16(199 + i%20)})
+		stats[2].add(result{score: uint16(100 + i%20)})
+		if i < 10 {
+			stats[3].add(result{score: 0})
+		}
+	}
+	ranked := make([]MoveStats, len(moves))
+	r := Racing{Z: 3, MinGames: 50}
+	left := r.race(Objective{By: ByMean}, moves, stats, ranked, []int{0, 1, 2, 3})
+
+	if want := []int{0, 1, 3}; !slices.Equal(left, want) {
+		t.Errorf("race() left moves %v; want %v", left, want)
+	}
+	for i, ms := range ranked {
+		if dropped := ms.Eliminated != ""; dropped != (i == 2) {
+			t.Errorf("move %d eliminated %q; want it dropped: %t", i, ms.Eliminated, i == 2)
+		}
+	}
+	if got := ranked[2].Eliminated; !strings.Contains(got, "after 100 playouts") || !strings.Contains(got, moves[0].String()) {
+		t.Errorf("move 2 eliminated %q; want the playouts it got and the move it lost to", got)
+	}
+
+	// Without enough playouts, no move is judged.
+	if got := (Racing{Z: 3, MinGames: 1000}).race(Objective{By: ByMean}, moves, stats, make([]MoveStats, len(moves)), []int{0, 1, 2, 3}); len(got) != 4 {
+		t.Errorf("race() with too few playouts left moves %v; want all of them", got)
+	}
+}
@@ -0,0 +1,26 @@
+// Package strategy has players that pick moves without a human.
+package strategy
+
+import (
+	"context"
+	"math/rand/v2"
+
+	"github.com/AustinJGreen/goyatzy/game"
+)
+
+// RandomPlayer picks every move uniformly at random.
+type RandomPlayer struct {
+	Rng *rand.Rand
+}
+
+func (rp *RandomPlayer) String() string { return "random player" }
+
+func (rp *RandomPlayer) PickMove(_ context.Context, _ *game.Game, moves []game.Move) int {
+	return rp.Rng.IntN(len(moves))
+}
+
+func (rp *RandomPlayer) MoveProbabilities(_ *game.Game, moves []game.Move, probs []float64) {
+	for i := range moves {
+		probs[i] = 1 / float64(len(moves))
+	}
+}
@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"bufio"
+	"io"
+)
+
+// key is a key pressed: a rune, or one of the special keys below.
+type key rune
+
+const (
+	keyUp    key = -1
+	keyDown  key = -2
+	keyEnter key = '\r'
+)
+
+// readKeys sends the keys read from r to keys until r fails, then closes
+// keys. r must be in raw mode, so keys arrive as they are pressed.
+func readKeys(r io.Reader, keys chan<- key) {
+	defer close(keys)
+	br := bufio.NewReader(r)
+	for {
+		c, _, err := br.ReadRune()
+		if err != nil {
+			return
+		}
+		switch c {
+		case '\x1b':
+			// Arrow keys are sent as ESC [ A and so on. Anything else
+			// starting with ESC is ignored.
+			if next, _, err := br.ReadRune(); err != nil || next != '[' {
+				continue
+			}
+			switch arrow, _, _ := br.ReadRune(); arrow {
+			case 'A':
+				keys <- keyUp
+			case 'B':
+				keys <- keyDown
+			}
+		case '\n':
+			keys <- keyEnter
+		case '\x03', '\x04':
+			// Ctrl-C and ctrl-D do not signal in raw mode.
+			keys <- 'q'
+		default:
+			keys <- key(c)
+		}
+	}
+}
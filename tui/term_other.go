@@ -0,0 +1,18 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package tui
+
+import (
+	"errors"
+	"runtime"
+)
+
+var errUnsupported = errors.New("raw mode is not supported on " + runtime.GOOS)
+
+func makeRaw(fd int) (func(), error) {
+	return nil, errUnsupported
+}
+
+func termSize(fd int) (rows, cols int, err error) {
+	return 0, 0, errUnsupported
+}
@@ -0,0 +1,308 @@
+// Package tui is a full-screen terminal interface for playing and watching
+// games. It shows every scorecard side by side, the dice and which are
+// held, what each open category would score now and on average after a
+// reroll, and the moves ranked by a Monte Carlo search.
+//
+// Players that are nil are humans at the keyboard, who play with:
+//
+//	1-5            hold or release a die
+//	r              reroll the dice not held
+//	up, down, k, j choose a category
+//	enter          select the chosen category
+//
+// Anyone can press p to pause or resume the bots and q to quit.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/render"
+	"github.com/AustinJGreen/goyatzy/scoring"
+	"github.com/AustinJGreen/goyatzy/strategy"
+)
+
+// Options configure the interface.
+type Options struct {
+	// Think is how long the Monte Carlo search runs for each decision, to
+	// suggest moves to humans and for bots that rank their moves.
+	Think time.Duration
+	// Delay is the least time each bot move is shown for, so that games
+	// between bots can be followed.
+	Delay time.Duration
+	// Style is how the scorecards are drawn, render.Unicode by default.
+	Style render.Style
+}
+
+// ranker is a player that ranks every move, such as
+// strategy.MonteCarloPlayer. Its ranking is shown as it moves.
+type ranker interface {
+	Rank(ctx context.Context, g *game.Game, moves []game.Move) []strategy.MoveStats
+}
+
+// Run plays g in the terminal of in and out until it is over and the
+// human quits. It switches the terminal to raw mode and the alternate
+// screen, restoring both when it returns.
+func Run(g *game.Game, in, out *os.File, opts Options) error {
+	restore, err := makeRaw(int(in.Fd()))
+	if err != nil {
+		return fmt.Errorf("terminal: %w", err)
+	}
+	defer restore()
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(out, "\x1b[?25h\x1b[?1049l")
+
+	u := newUI(g, opts)
+	if _, width, err := termSize(int(out.Fd())); err == nil {
+		u.width = width
+	}
+	keys := make(chan key)
+	go readKeys(in, keys)
+	return u.loop(keys, out)
+}
+
+type ui struct {
+	g     *game.Game
+	opts  Options
+	width int // of the terminal, or 0 if unknown
+
+	// held marks the dice of the current roll a human will keep.
+	held [5]bool
+	// cursor is the index of the chosen category among the open ones.
+	cursor int
+	// decision counts the decisions made, so that searches started for
+	// an earlier one are ignored.
+	decision int
+	thinking bool
+	// ranked is the ranking of the moves for the current decision, once
+	// the search is done.
+	ranked []strategy.MoveStats
+	// pending is the move a bot picked, made once it has been shown until
+	// due, or -1.
+	pending int
+	due     time.Time
+	paused  bool
+	// status is a line about the last thing that happened.
+	status string
+}
+
+func newUI(g *game.Game, opts Options) *ui {
+	if opts.Style == (render.Style{}) {
+		opts.Style = render.Unicode
+	}
+	return &ui{g: g, opts: opts, pending: -1}
+}
+
+// searchResult is the outcome of thinking about a decision.
+type searchResult struct {
+	decision int
+	ranked   []strategy.MoveStats
+	// moveIdx is the move a bot picked, or -1 for a human.
+	moveIdx int
+}
+
+func (u *ui) humanToMove() bool {
+	return !u.g.IsOver() && u.g.Players[u.g.CurPlayerIdx] == nil
+}
+
+// loop draws the game and handles keys and finished searches until the
+// human quits or keys is closed.
+func (u *ui) loop(keys <-chan key, out io.Writer) error {
+	results := make(chan searchResult)
+	stop := func() {}
+	defer func() { stop() }()
+	for {
+		if !u.g.IsOver() {
+			if u.g.CurTurn.RollCnt == 0 {
+				if err := u.g.EnterRoll(0, u.g.RandRoll()); err != nil {
+					return err
+				}
+			}
+			if !u.thinking && u.ranked == nil && u.pending < 0 && (u.humanToMove() || !u.paused) {
+				stop()
+				stop = u.search(results)
+			}
+		}
+		if err := u.draw(out); err != nil {
+			return err
+		}
+
+		var wait <-chan time.Time
+		if u.pending >= 0 && !u.paused {
+			wait = time.After(time.Until(u.due))
+		}
+		select {
+		case k, ok := <-keys:
+			if !ok || u.key(k) {
+				return nil
+			}
+		case res := <-results:
+			if res.decision != u.decision {
+				continue
+			}
+			u.thinking = false
+			u.ranked = res.ranked
+			u.pending = res.moveIdx
+		case <-wait:
+			moves := u.g.GetMovesForCurrentPlayer(u.g.CurTurn.CurrentRoll, nil)
+			if u.pending >= len(moves) {
+				return fmt.Errorf("player [%s]: %w: index %d of %d", u.g.Players[u.g.CurPlayerIdx], game.ErrUnknownMove, u.pending, len(moves))
+			}
+			if err := u.play(moves[u.pending]); err != nil {
+				return fmt.Errorf("player [%s]: %w", u.g.Players[u.g.CurPlayerIdx], err)
+			}
+		}
+	}
+}
+
+// search thinks about the current decision in the background, on a copy
+// of the game with its own rng, and sends the result to results. It
+// returns a function that abandons the search.
+func (u *ui) search(results chan<- searchResult) func() {
+	alive, cancel := context.WithCancel(context.Background())
+	g := u.g.Clone()
+	g.Src = rand.NewPCG(u.g.Rng.Uint64(), u.g.Rng.Uint64())
+	g.Rng = rand.New(g.Src)
+	p := u.g.Players[u.g.CurPlayerIdx]
+	moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+	res := searchResult{decision: u.decision, moveIdx: -1}
+	u.thinking = true
+	u.due = time.Now().Add(u.opts.Delay)
+
+	go func() {
+		ctx, done := context.WithTimeout(alive, u.opts.Think)
+		defer done()
+		switch p := p.(type) {
+		case nil:
+			res.ranked = (&strategy.MonteCarloPlayer{Rng: g.Rng}).Rank(ctx, g, moves)
+		case ranker:
+			res.ranked = p.Rank(ctx, g, moves)
+			res.moveIdx = res.ranked[0].Index
+		default:
+			res.moveIdx = p.PickMove(ctx, g, moves)
+		}
+		select {
+		case results <- res:
+		case <-alive.Done():
+		}
+	}()
+	return cancel
+}
+
+// key handles a key pressed, returning whether to quit.
+func (u *ui) key(k key) bool {
+	u.status = ""
+	switch k {
+	case 'q':
+		return true
+	case 'p':
+		u.paused = !u.paused
+		if u.paused {
+			u.status = "paused"
+		}
+		return false
+	}
+	if !u.humanToMove() {
+		if u.g.IsOver() {
+			u.status = "the game is over, press q to quit"
+		} else {
+			u.status = fmt.Sprintf("waiting for player [%s]", u.g.Players[u.g.CurPlayerIdx])
+		}
+		return false
+	}
+
+	open := u.openCategories()
+	switch {
+	case k >= '1' && k <= '5':
+		u.held[k-'1'] = !u.held[k-'1']
+	case k == 'r':
+		held := u.heldDice()
+		if len(held) == 0 {
+			// Rerolling every die is not a move.
+			u.status = fmt.Sprintf("%v to reroll the rest (1-5 hold)", game.ErrEmptyHold)
+			return false
+		}
+		u.tryPlay(game.NewRerollMove(held...))
+	case k == keyUp || k == 'k':
+		u.cursor = (u.cursor + len(open) - 1) % len(open)
+	case k == keyDown || k == 'j':
+		u.cursor = (u.cursor + 1) % len(open)
+	case k == keyEnter:
+		u.tryPlay(u.g.SelectMove(open[u.cursor]))
+	default:
+		u.status = "keys: 1-5 hold, r reroll, up/down choose, enter select, p pause, q quit"
+	}
+	return false
+}
+
+// tryPlay plays a move made by a human, showing why if it is not legal.
+func (u *ui) tryPlay(m game.Move) {
+	if !slices.Contains(u.g.GetMovesForCurrentPlayer(u.g.CurTurn.CurrentRoll, nil), m) {
+		if err := u.g.ValidateMove(m); err != nil {
+			u.status = err.Error()
+		} else {
+			u.status = fmt.Sprintf("%s is not one of the available moves", m)
+		}
+		return
+	}
+	if err := u.play(m); err != nil {
+		u.status = err.Error()
+	}
+}
+
+// play plays a legal move for the current player and moves on to the
+// next decision.
+func (u *ui) play(m game.Move) error {
+	pIdx := u.g.CurPlayerIdx
+	if _, err := u.g.Play(m); err != nil {
+		return err
+	}
+	u.status = fmt.Sprintf("player %d [%s]: %s", pIdx+1, playerName(u.g.Players[pIdx]), m)
+	u.decision++
+	u.thinking = false
+	u.ranked = nil
+	u.pending = -1
+	u.held = [5]bool{}
+	if !m.Reroll {
+		u.cursor = 0
+	}
+	return nil
+}
+
+// heldDice returns the dice the human holds.
+func (u *ui) heldDice() []dice.Die {
+	var held []dice.Die
+	for i, h := range u.held {
+		if h {
+			held = append(held, u.g.CurTurn.CurrentRoll.Die(i))
+		}
+	}
+	return held
+}
+
+// openCategories returns the categories the current player has not
+// filled.
+func (u *ui) openCategories() []scoring.Category {
+	ps := u.g.Scorecards[u.g.CurPlayerIdx]
+	var open []scoring.Category
+	for c := range scoring.Category(scoring.Categories) {
+		if ps.CatMask&(1<<c) == 0 {
+			open = append(open, c)
+		}
+	}
+	return open
+}
+
+func playerName(p game.Player) string {
+	if p == nil {
+		return "human"
+	}
+	return fmt.Sprint(p)
+}
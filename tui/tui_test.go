@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/scoring"
+	"github.com/AustinJGreen/goyatzy/strategy"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadKeys(t *testing.T) {
+	keys := make(chan key)
+	go readKeys(strings.NewReader("12\x1b[A\x1b[B\x1bxr\r\n\x03"), keys)
+	var got []key
+	for k := range keys {
+		got = append(got, k)
+	}
+	want := []key{'1', '2', keyUp, keyDown, 'r', keyEnter, keyEnter, 'q'}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("readKeys() returned unexpected keys (-got, +want):\n%s", diff)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	g := game.New(rand.NewPCG(1, 2), make([]game.Player, 1))
+	if err := g.EnterRoll(0, dice.NewRoll(5, 2, 5, 1, 3)); err != nil {
+		t.Fatal(err)
+	}
+	u := newUI(g, Options{})
+
+	u.key('r')
+	if g.CurTurn.RollCnt != 1 || !strings.Contains(u.status, "hold at least one die") {
+		t.Errorf("after r with nothing held, turn is %+v and status %q; want no reroll and to be told to hold a die", g.CurTurn, u.status)
+	}
+
+	for _, k := range []key{'1', '3', '2', '2'} {
+		u.key(k)
+	}
+	if got, want := u.heldDice(), []dice.Die{5, 5}; !slices.Equal(got, want) {
+		t.Errorf("held %v after toggling; want %v", got, want)
+	}
+	if view := strings.Join(u.view(), "\n"); !strings.Contains(view, "[5]  2  [5]  1   3") {
+		t.Errorf("view does not show the held dice:\n%s", view)
+	}
+
+	u.key('r')
+	if g.CurTurn.RollCnt != 2 || g.CurTurn.CurrentRoll.Counts().Count(dice.DIE_FIVE) < 2 {
+		t.Fatalf("after r, turn is %+v; want the fives rerolled around", g.CurTurn)
+	}
+	if u.held != [5]bool{} {
+		t.Errorf("dice still held after rerolling: %v", u.held)
+	}
+
+	u.key(keyDown)
+	u.key(keyDown)
+	u.key(keyUp)
+	u.key(keyEnter)
+	if ps := g.Scorecards[0]; ps.CatMask != 1<<scoring.CAT_TWOS {
+		t.Errorf("after selecting the second category, filled %b; want only twos", ps.CatMask)
+	}
+	if g.CurTurn.RollCnt != 0 {
+		t.Errorf("turn not over after selecting: %+v", g.CurTurn)
+	}
+
+	if !u.key('q') {
+		t.Error("q did not quit")
+	}
+}
+
+// screen records what is drawn so tests can wait for something to show.
+type screen struct {
+	frames chan string
+}
+
+func (s *screen) Write(p []byte) (int, error) {
+	s.frames <- string(p)
+	return len(p), nil
+}
+
+func (s *screen) waitFor(t *testing.T, text string) string {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case frame := <-s.frames:
+			if strings.Contains(frame, text) {
+				return frame
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %q", text)
+		}
+	}
+}
+
+// quit presses q, drawing on regardless, and checks the loop ends well.
+func (s *screen) quit(t *testing.T, keys chan<- key, done <-chan error) {
+	t.Helper()
+	go func() {
+		for range s.frames {
+		}
+	}()
+	keys <- 'q'
+	if err := <-done; err != nil {
+		t.Errorf("loop() returned unexpected error: %v", err)
+	}
+	close(s.frames)
+}
+
+func TestWatchBots(t *testing.T) {
+	g := game.New(rand.NewPCG(1, 2), make([]game.Player, 2))
+	g.Players = []game.Player{&strategy.RandomPlayer{Rng: g.Rng}, &strategy.MonteCarloPlayer{Rng: g.Rng}}
+	keys := make(chan key)
+	s := &screen{frames: make(chan string)}
+	done := make(chan error)
+	go func() { done <- newUI(g, Options{Think: time.Millisecond}).loop(keys, s) }()
+
+	s.waitFor(t, "best moves after")
+	frame := s.waitFor(t, "game over")
+	if !strings.Contains(frame, "total") {
+		t.Errorf("final screen has no totals:\n%s", frame)
+	}
+	s.quit(t, keys, done)
+}
+
+func TestSuggestions(t *testing.T) {
+	g := game.New(rand.NewPCG(1, 2), make([]game.Player, 1))
+	keys := make(chan key)
+	s := &screen{frames: make(chan string)}
+	done := make(chan error)
+	go func() { done <- newUI(g, Options{Think: 20 * time.Millisecond}).loop(keys, s) }()
+
+	frame := s.waitFor(t, "best moves after")
+	if !strings.Contains(frame, "> ones") {
+		t.Errorf("the first category is not chosen:\n%s", frame)
+	}
+	keys <- keyEnter
+	s.waitFor(t, "player 1 [human]: select ones")
+	s.quit(t, keys, done)
+}
@@ -0,0 +1,175 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/render"
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+// maxRanked is how many ranked moves are shown.
+const maxRanked = 6
+
+// draw redraws the whole screen.
+func (u *ui) draw(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for _, line := range u.view() {
+		if u.width > 0 && len([]rune(line)) > u.width {
+			line = string([]rune(line)[:u.width])
+		}
+		b.WriteString(line)
+		b.WriteString("\x1b[K\r\n")
+	}
+	b.WriteString("\x1b[J")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// view returns the lines of the screen: the scorecards, with the dice,
+// the categories and the ranked moves beside them while the game is on.
+func (u *ui) view() []string {
+	lines := []string{u.title(), ""}
+	var panel []string
+	if !u.g.IsOver() {
+		panel = append(panel, u.diceLines()...)
+		panel = append(panel, "")
+		panel = append(panel, u.categories()...)
+		panel = append(panel, "")
+		panel = append(panel, u.rankedMoves()...)
+	}
+	lines = append(lines, sideBySide(u.scorecards(), panel)...)
+	lines = append(lines, "", u.status)
+	return lines
+}
+
+func (u *ui) title() string {
+	g := u.g
+	if g.IsOver() {
+		best := 0
+		for i, ps := range g.Scorecards {
+			if ps.Score() > g.Scorecards[best].Score() {
+				best = i
+			}
+		}
+		return fmt.Sprintf("game over, player %d [%s] wins with %d points", best+1, playerName(g.Players[best]), g.Scorecards[best].Score())
+	}
+	title := fmt.Sprintf("player %d [%s] to move", g.CurPlayerIdx+1, playerName(g.Players[g.CurPlayerIdx]))
+	if u.paused {
+		title += " (paused)"
+	}
+	return title
+}
+
+// scorecards draws every scorecard in a column, marking the player to
+// move.
+func (u *ui) scorecards() []string {
+	names := make([]string, len(u.g.Players))
+	for i, p := range u.g.Players {
+		names[i] = fmt.Sprintf("%d %s", i+1, playerName(p))
+		if i == u.g.CurPlayerIdx && !u.g.IsOver() {
+			names[i] = "*" + names[i]
+		}
+	}
+	return strings.Split(render.Scorecards(u.opts.Style, names, u.g.Scorecards), "\n")
+}
+
+// diceLines shows the current roll, with held dice in brackets.
+func (u *ui) diceLines() []string {
+	t := u.g.CurTurn
+	line := "dice    "
+	for i := range 5 {
+		if u.held[i] {
+			line += fmt.Sprintf("[%d] ", t.CurrentRoll.Die(i))
+		} else {
+			line += fmt.Sprintf(" %d  ", t.CurrentRoll.Die(i))
+		}
+	}
+	return []string{
+		line,
+		"key      1   2   3   4   5",
+		fmt.Sprintf("roll %d of %d, %d left", t.RollCnt, scoring.MaxReRolls, scoring.MaxReRolls-t.RollCnt),
+	}
+}
+
+// categories shows what each open category scores now and, if there are
+// rolls left, on average when rerolling the dice not held and then
+// selecting it.
+func (u *ui) categories() []string {
+	t := u.g.CurTurn
+	rollsLeft := scoring.MaxReRolls - t.RollCnt
+	var odds [scoring.Categories]scoring.CategoryOdds
+	if rollsLeft > 0 {
+		var err error
+		if odds, err = scoring.RollOdds(t.CurrentRoll, u.heldCounts(), rollsLeft); err != nil {
+			rollsLeft = 0
+		}
+	}
+
+	lines := []string{fmt.Sprintf("  %-16s %5s %7s", "category", "now", "reroll")}
+	for i, c := range u.openCategories() {
+		cursor := " "
+		if u.humanToMove() && i == u.cursor {
+			cursor = ">"
+		}
+		avg := "-"
+		if rollsLeft > 0 {
+			avg = fmt.Sprintf("%.1f", odds[c].Mean())
+		}
+		lines = append(lines, fmt.Sprintf("%s %-16s %5d %7s", cursor, c, u.g.SelectMove(c).Score, avg))
+	}
+	return lines
+}
+
+// rankedMoves shows the best moves found by the search.
+func (u *ui) rankedMoves() []string {
+	switch {
+	case u.thinking:
+		return []string{"thinking..."}
+	case u.ranked == nil:
+		return nil
+	case u.ranked[0].Unranked:
+		return []string{"no playouts finished in time"}
+	}
+	var games uint64
+	for _, ms := range u.ranked {
+		games += ms.Games
+	}
+	lines := []string{fmt.Sprintf("best moves after %d playouts", games)}
+	for i, ms := range u.ranked[:min(maxRanked, len(u.ranked))] {
+		lines = append(lines, fmt.Sprintf("%d. %-32s avg %6.1f  top %6.1f  won %3.0f%%", i+1, ms.Move, ms.Mean, ms.TopMean, 100*ms.WinRate))
+	}
+	return lines
+}
+
+func (u *ui) heldCounts() dice.Counts {
+	return dice.Count(u.heldDice()...)
+}
+
+// sideBySide lays out the lines of right to the right of those of left.
+func sideBySide(left, right []string) []string {
+	width := 0
+	for _, line := range left {
+		width = max(width, utf8.RuneCountInString(line))
+	}
+	lines := make([]string, max(len(left), len(right)))
+	for i := range lines {
+		var l, r string
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		if r == "" {
+			lines[i] = l
+			continue
+		}
+		lines[i] = l + strings.Repeat(" ", width-utf8.RuneCountInString(l)) + "   " + r
+	}
+	return lines
+}
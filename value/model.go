@@ -0,0 +1,136 @@
+// Package value learns how many more points a scorecard will score, from
+// games a player plays against itself, and plays by looking ahead to the
+// end of each turn and valuing the scorecards it could end with.
+//
+// The model is linear in a few features of a scorecard (see Features),
+// so it is quick to fit and to evaluate on a CPU. Its weights are saved
+// as JSON:
+//
+//	{"version": 1, "features": ["bias", "open ones", ...], "weights": [12.5, 3.1, ...]}
+package value
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+// formatVersion is the version of the saved weights format.
+const formatVersion = 1
+
+// ErrInvalidModel is returned for loading weights that are not a model of
+// this version's features.
+var ErrInvalidModel = errors.New("invalid model")
+
+// featureNames names each feature, in order.
+var featureNames = func() []string {
+	names := []string{"bias"}
+	for c := range scoring.Category(scoring.Categories) {
+		names = append(names, "open "+c.String())
+	}
+	return append(names, "upper progress", "upper bonus earned", "yatzy scored", "turns left")
+}()
+
+// NumFeatures is the number of features of a scorecard.
+var NumFeatures = len(featureNames)
+
+// Features appends the features of ps to buf: a constant bias, whether
+// each category is open, the upper section total as a fraction of what
+// the bonus needs, whether the bonus has been earned, whether yatzy has
+// been scored (so later yatzies earn a bonus) and the fraction of turns
+// left.
+func Features(ps scoring.Scorecard, buf []float64) []float64 {
+	buf = append(buf, 1)
+	for c := range scoring.Category(scoring.Categories) {
+		buf = append(buf, flag(ps.CatMask&(1<<c) == 0))
+	}
+	upper := ps.UpperSum()
+	return append(buf,
+		float64(min(upper, scoring.UpperSectionMinBonusSum))/scoring.UpperSectionMinBonusSum,
+		flag(upper >= scoring.UpperSectionMinBonusSum),
+		flag(ps.ScoresByCategory[scoring.CAT_YATZY] > 0),
+		float64(ps.GetTurnsLeft())/scoring.Categories,
+	)
+}
+
+func flag(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Model values scorecards with a weight for each feature.
+type Model struct {
+	Weights []float64
+}
+
+// Remaining returns how many more points ps is expected to score,
+// including any upper section bonus.
+func (m *Model) Remaining(ps scoring.Scorecard) float64 {
+	if ps.CatMask == scoring.AllFilled {
+		return float64(ps.Score() - ps.RawSum())
+	}
+	var buf [32]float64
+	var v float64
+	for i, f := range Features(ps, buf[:0]) {
+		v += m.Weights[i] * f
+	}
+	return v
+}
+
+// Value returns the final score ps is expected to finish with.
+func (m *Model) Value(ps scoring.Scorecard) float64 {
+	return float64(ps.RawSum()) + m.Remaining(ps)
+}
+
+type savedModel struct {
+	Version  int       `json:"version"`
+	Features []string  `json:"features"`
+	Weights  []float64 `json:"weights"`
+}
+
+// Save writes the weights of m to w.
+func (m *Model) Save(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(savedModel{Version: formatVersion, Features: featureNames, Weights: m.Weights})
+}
+
+// Load reads a model saved with Save from r.
+func Load(r io.Reader) (*Model, error) {
+	var saved savedModel
+	if err := json.NewDecoder(r).Decode(&saved); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidModel, err)
+	}
+	switch {
+	case saved.Version != formatVersion:
+		return nil, fmt.Errorf("%w: version %d (want %d)", ErrInvalidModel, saved.Version, formatVersion)
+	case !slices.Equal(saved.Features, featureNames):
+		return nil, fmt.Errorf("%w: features %q (want %q)", ErrInvalidModel, saved.Features, featureNames)
+	case len(saved.Weights) != NumFeatures:
+		return nil, fmt.Errorf("%w: %d weights for %d features", ErrInvalidModel, len(saved.Weights), NumFeatures)
+	}
+	return &Model{Weights: saved.Weights}, nil
+}
+
+// defaultWeights are the weights trained with the train command.
+//
+//go:embed weights.json
+var defaultWeights string
+
+// Default returns the model trained with the train command and built in.
+var Default = sync.OnceValue(func() *Model {
+	m, err := Load(strings.NewReader(defaultWeights))
+	if err != nil {
+		panic(fmt.Sprintf("value: built in weights: %v", err))
+	}
+	return m
+})
@@ -0,0 +1,82 @@
+package value
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+func TestFeatures(t *testing.T) {
+	var ps scoring.Scorecard
+	ps = ps.Update(dice.NewRoll(6, 6, 6, 6, 6), scoring.CAT_YATZY)
+	ps = ps.Update(dice.NewRoll(6, 6, 6, 6, 6), scoring.CAT_SIXES)
+	ps = ps.Update(dice.NewRoll(5, 5, 5, 5, 5), scoring.CAT_FIVES)
+
+	want := []float64{1,
+		1, 1, 1, 1, 0, 0, // ones to sixes
+		1, 1, 1, 1, 1, 1, 0, // three of a kind to yatzy
+		55.0 / 63, 0, 1, 10.0 / 13,
+	}
+	if diff := cmp.Diff(Features(ps, nil), want); diff != "" {
+		t.Errorf("Features() mismatch (-got, +want):\n%s", diff)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	m := &Model{Weights: make([]float64, NumFeatures)}
+	for i := range m.Weights {
+		m.Weights[i] = float64(i) / 3
+	}
+	var buf bytes.Buffer
+	if err := m.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(&buf)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(got, m); diff != "" {
+		t.Errorf("Load() of saved model mismatch (-got, +want):\n%s", diff)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&Model{Weights: make([]float64, NumFeatures)}).Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	saved := buf.String()
+	for _, tt := range []struct {
+		name  string
+		saved string
+	}{
+		{"not json", "weights"},
+		{"version", strings.Replace(saved, `"version": 1`, `"version": 2`, 1)},
+		{"features", strings.Replace(saved, `"open yatzy"`, `"open yahtzee"`, 1)},
+		{"weights", strings.Replace(saved, "\t\t0,\n", "", 1)},
+	} {
+		if _, err := Load(strings.NewReader(tt.saved)); !errors.Is(err, ErrInvalidModel) {
+			t.Errorf("Load() of bad %s returned error %v; want %v", tt.name, err, ErrInvalidModel)
+		}
+	}
+}
+
+func TestDefault(t *testing.T) {
+	m := Default()
+	if v := m.Value(scoring.Scorecard{}); v < 200 || v > 300 {
+		t.Errorf("Default().Value() of an empty scorecard = %v; want between 200 and 300", v)
+	}
+
+	// A full scorecard is worth exactly its score.
+	ps := scoring.Scorecard{CatMask: scoring.AllFilled}
+	ps.ScoresByCategory[scoring.CAT_SIXES] = 63
+	if got, want := m.Value(ps), 98.0; got != want {
+		t.Errorf("Default().Value() of a full scorecard = %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,82 @@
+package value
+
+import (
+	"context"
+	"math"
+
+	"github.com/AustinJGreen/goyatzy/dice"
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+// Player looks ahead to the end of the turn: it values every category it
+// could fill by the points it scores and what Model expects the
+// scorecard to score after, and every reroll by its expected value over
+// the rest of the turn.
+type Player struct {
+	Model *Model
+}
+
+func (p *Player) String() string { return "value player" }
+
+func (p *Player) PickMove(_ context.Context, g *game.Game, moves []game.Move) int {
+	return p.best(g, moves)
+}
+
+// MoveProbabilities puts all the probability on the move PickMove picks,
+// so games played by the player can be scored exactly (see
+// game.FinalScoreDistribution).
+func (p *Player) MoveProbabilities(g *game.Game, moves []game.Move, probs []float64) {
+	best := p.best(g, moves)
+	for i := range moves {
+		probs[i] = 0
+	}
+	probs[best] = 1
+}
+
+// MoveValues returns the final score the current player of g is expected
+// to finish with after each of moves.
+func (p *Player) MoveValues(g *game.Game, moves []game.Move) []float64 {
+	ps := g.Scorecards[g.CurPlayerIdx]
+	rollsLeft := scoring.MaxReRolls - g.CurTurn.RollCnt
+	var stages *[scoring.MaxReRolls][dice.DistinctRolls]float64
+	if rollsLeft > 0 {
+		stages = p.stages(ps)
+	}
+	values := make([]float64, len(moves))
+	for i, m := range moves {
+		if m.Reroll {
+			values[i] = dice.ExpectedValue(&stages[rollsLeft-1], m.Hold)
+		} else {
+			values[i] = p.Model.Value(ps.Update(g.CurTurn.CurrentRoll, m.Cat))
+		}
+	}
+	return values
+}
+
+func (p *Player) best(g *game.Game, moves []game.Move) int {
+	best, bestValue := 0, math.Inf(-1)
+	for i, v := range p.MoveValues(g, moves) {
+		if v > bestValue {
+			best, bestValue = i, v
+		}
+	}
+	return best
+}
+
+// stages returns the value of every roll of a turn of ps with each number
+// of rerolls left, indexed by dice.Roll.Key.
+func (p *Player) stages(ps scoring.Scorecard) *[scoring.MaxReRolls][dice.DistinctRolls]float64 {
+	stages := new([scoring.MaxReRolls][dice.DistinctRolls]float64)
+	for rk, r := range dice.SortedRolls() {
+		best := math.Inf(-1)
+		for c := range scoring.Category(scoring.Categories) {
+			if ps.CatMask&(1<<c) == 0 {
+				best = max(best, p.Model.Value(ps.Update(r, c)))
+			}
+		}
+		stages[0][rk] = best
+	}
+	dice.MaxStages(stages[:], false)
+	return stages
+}
@@ -0,0 +1,37 @@
+package value
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/scoring"
+)
+
+func TestPlayer(t *testing.T) {
+	p := &Player{Model: Default()}
+	for _, tt := range []struct {
+		position string
+		want     func(g *game.Game) game.Move
+	}{
+		{"- 66666 3 1", func(g *game.Game) game.Move { return g.SelectMove(scoring.CAT_YATZY) }},
+		{"- 23456 3 1", func(g *game.Game) game.Move { return g.SelectMove(scoring.CAT_LARGE_STRAIGHT) }},
+		{"- 66661 1 1", func(g *game.Game) game.Move { return game.NewRerollMove(6, 6, 6, 6) }},
+	} {
+		g, err := game.ParsePosition(tt.position)
+		if err != nil {
+			t.Fatal(err)
+		}
+		moves := g.GetMovesForCurrentPlayer(g.CurTurn.CurrentRoll, nil)
+		best := p.PickMove(context.Background(), g, moves)
+		if got, want := moves[best], tt.want(g); got != want {
+			t.Errorf("PickMove() at %s picked %s; want %s", tt.position, got, want)
+		}
+
+		probs := make([]float64, len(moves))
+		p.MoveProbabilities(g, moves, probs)
+		if probs[best] != 1 {
+			t.Errorf("MoveProbabilities() at %s gave %s probability %v; want 1", tt.position, moves[best], probs[best])
+		}
+	}
+}
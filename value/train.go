@@ -0,0 +1,236 @@
+package value
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+
+	"github.com/AustinJGreen/goyatzy/game"
+	"github.com/AustinJGreen/goyatzy/scoring"
+	"github.com/AustinJGreen/goyatzy/strategy"
+)
+
+// Sample is a scorecard at the start of a turn and the points it went on
+// to score, including any upper section bonus.
+type Sample struct {
+	Scorecard scoring.Scorecard
+	Remaining float64
+}
+
+// recorder plays as Player and records the scorecard at the start of
+// every turn.
+type recorder struct {
+	game.Player
+	seen []scoring.Scorecard
+}
+
+func (r *recorder) PickMove(ctx context.Context, g *game.Game, moves []game.Move) int {
+	if g.CurTurn.RollCnt == 1 {
+		r.seen = append(r.seen, g.Scorecards[g.CurPlayerIdx])
+	}
+	return r.Player.PickMove(ctx, g, moves)
+}
+
+// SelfPlay plays games single player games with p, rolling with src, and
+// returns a sample for the start of every turn. It stops early if ctx is
+// done.
+func SelfPlay(ctx context.Context, src *rand.PCG, p game.Player, games int) []Sample {
+	var samples []Sample
+	for range games {
+		if ctx.Err() != nil {
+			break
+		}
+		rec := &recorder{Player: p}
+		g := game.New(src, []game.Player{rec})
+		g.RunSimulation(ctx)
+		if !g.IsOver() {
+			break
+		}
+		final := g.Scorecards[0].Score()
+		for _, ps := range rec.seen {
+			samples = append(samples, Sample{Scorecard: ps, Remaining: float64(final - ps.RawSum())})
+		}
+	}
+	return samples
+}
+
+// ErrSingular is returned for fitting a model to samples that do not
+// determine it.
+var ErrSingular = errors.New("samples do not determine the model")
+
+// Fit returns the model with the least squared error over samples, with
+// ridge times the sum of the squared weights added to keep them small
+// (and unique: the features are not independent, since the turns left is
+// the number of open categories).
+func Fit(samples []Sample, ridge float64) (*Model, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("%w: no samples", ErrSingular)
+	}
+	// Solve the normal equations (XᵀX/n + ridge·I) w = Xᵀy/n.
+	n := NumFeatures
+	a := make([][]float64, n)
+	for i := range a {
+		a[i] = make([]float64, n+1)
+	}
+	buf := make([]float64, 0, n)
+	for _, s := range samples {
+		x := Features(s.Scorecard, buf[:0])
+		for i := range n {
+			for j := range n {
+				a[i][j] += x[i] * x[j]
+			}
+			a[i][n] += x[i] * s.Remaining
+		}
+	}
+	for i := range n {
+		for j := range n + 1 {
+			a[i][j] /= float64(len(samples))
+		}
+		a[i][i] += ridge
+	}
+
+	// Gaussian elimination with partial pivoting.
+	for col := range n {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, fmt.Errorf("%w: feature %q", ErrSingular, featureNames[col])
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		for row := col + 1; row < n; row++ {
+			f := a[row][col] / a[col][col]
+			for j := col; j <= n; j++ {
+				a[row][j] -= f * a[col][j]
+			}
+		}
+	}
+	w := make([]float64, n)
+	for i := n - 1; i >= 0; i-- {
+		sum := a[i][n]
+		for j := i + 1; j < n; j++ {
+			sum -= a[i][j] * w[j]
+		}
+		w[i] = sum / a[i][i]
+	}
+	return &Model{Weights: w}, nil
+}
+
+// MeanSquaredError returns the mean squared error of m over samples.
+func (m *Model) MeanSquaredError(samples []Sample) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, s := range samples {
+		d := m.Remaining(s.Scorecard) - s.Remaining
+		sum += d * d
+	}
+	return sum / float64(len(samples))
+}
+
+// TrainOptions configure training.
+type TrainOptions struct {
+	// Games is how many games are played each round.
+	Games int
+	// Rounds is how many times the model is fit to games played with the
+	// last one. The first round is played by a random player.
+	Rounds int
+	// Ridge keeps the weights small (see Fit).
+	Ridge float64
+	// Progress, if set, is called after every round.
+	Progress func(Round)
+}
+
+// Round is how a round of training went.
+type Round struct {
+	Round int
+	// MeanScore is the mean final score of the games played, by the model
+	// of the round before.
+	MeanScore float64
+	Samples   int
+	// MSE is the mean squared error of the model fit this round, over the
+	// samples it was fit to.
+	MSE float64
+}
+
+// Train fits a model to games played by the model of the round before,
+// for opts.Rounds rounds, rolling with sources seeded from rng. Games are
+// played on every CPU. Refitting to a model's own games does not always
+// improve it, so Train returns the model whose games scored most; the
+// model fit in the last round has not played, so it is only returned if
+// no other has. It returns an error if ctx is done before a model is fit.
+func Train(ctx context.Context, rng *rand.Rand, opts TrainOptions) (*Model, error) {
+	var m, best *Model
+	bestScore := math.Inf(-1)
+	for round := range opts.Rounds {
+		newPlayer := func(rng *rand.Rand) game.Player { return &strategy.RandomPlayer{Rng: rng} }
+		if m != nil {
+			newPlayer = func(*rand.Rand) game.Player { return &Player{Model: m} }
+		}
+		samples := selfPlayParallel(ctx, rng, newPlayer, opts.Games)
+		if ctx.Err() != nil {
+			break
+		}
+		r := Round{Round: round, Samples: len(samples)}
+		for _, s := range samples {
+			if s.Scorecard.CatMask == 0 {
+				r.MeanScore += s.Remaining
+			}
+		}
+		r.MeanScore /= float64(opts.Games)
+		if m != nil && r.MeanScore > bestScore {
+			best, bestScore = m, r.MeanScore
+		}
+
+		next, err := Fit(samples, opts.Ridge)
+		if err != nil {
+			return nil, err
+		}
+		m = next
+		if opts.Progress != nil {
+			r.MSE = m.MeanSquaredError(samples)
+			opts.Progress(r)
+		}
+	}
+	switch {
+	case best != nil:
+		return best, nil
+	case m != nil:
+		return m, nil
+	}
+	return nil, ctx.Err()
+}
+
+// selfPlayParallel plays games split between a worker for every CPU,
+// each with its own source and player.
+func selfPlayParallel(ctx context.Context, rng *rand.Rand, newPlayer func(*rand.Rand) game.Player, games int) []Sample {
+	workers := runtime.GOMAXPROCS(0)
+	results := make([][]Sample, workers)
+	var wg sync.WaitGroup
+	for w := range workers {
+		n := games / workers
+		if w < games%workers {
+			n++
+		}
+		src := rand.NewPCG(rng.Uint64(), rng.Uint64())
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[w] = SelfPlay(ctx, src, newPlayer(rand.New(src)), n)
+		}()
+	}
+	wg.Wait()
+	var samples []Sample
+	for _, r := range results {
+		samples = append(samples, r...)
+	}
+	return samples
+}
@@ -0,0 +1,82 @@
+package value
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/AustinJGreen/goyatzy/scoring"
+	"github.com/AustinJGreen/goyatzy/strategy"
+)
+
+func TestSelfPlay(t *testing.T) {
+	src := rand.NewPCG(1, 2)
+	samples := SelfPlay(context.Background(), src, &strategy.RandomPlayer{Rng: rand.New(src)}, 3)
+	if got, want := len(samples), 3*scoring.Categories; got != want {
+		t.Fatalf("SelfPlay() returned %d samples; want one for each of %d turns", got, want)
+	}
+	for i, s := range samples {
+		if got, want := s.Scorecard.GetTurnsLeft(), scoring.Categories-i%scoring.Categories; got != want {
+			t.Errorf("sample %d has %d turns left; want %d", i, got, want)
+		}
+		if s.Remaining < 0 {
+			t.Errorf("sample %d has %v points remaining; want at least 0", i, s.Remaining)
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	src := rand.NewPCG(3, 4)
+	samples := SelfPlay(context.Background(), src, &strategy.RandomPlayer{Rng: rand.New(src)}, 50)
+
+	// Labelled by a model, the samples are fit with no error.
+	want := &Model{Weights: make([]float64, NumFeatures)}
+	for i := range want.Weights {
+		want.Weights[i] = float64(i*7%11) - 3
+	}
+	for i := range samples {
+		samples[i].Remaining = want.Remaining(samples[i].Scorecard)
+	}
+	m, err := Fit(samples, 1e-9)
+	if err != nil {
+		t.Fatalf("Fit() returned unexpected error: %v", err)
+	}
+	if mse := m.MeanSquaredError(samples); mse > 1e-6 {
+		t.Errorf("fit model has mean squared error %v; want 0", mse)
+	}
+
+	if _, err := Fit(nil, 1); !errors.Is(err, ErrSingular) {
+		t.Errorf("Fit() of no samples returned error %v; want %v", err, ErrSingular)
+	}
+}
+
+func TestTrain(t *testing.T) {
+	var rounds []Round
+	m, err := Train(context.Background(), rand.New(rand.NewPCG(5, 6)), TrainOptions{
+		Games:    20,
+		Rounds:   2,
+		Ridge:    1e-3,
+		Progress: func(r Round) { rounds = append(rounds, r) },
+	})
+	if err != nil {
+		t.Fatalf("Train() returned unexpected error: %v", err)
+	}
+	if len(rounds) != 2 {
+		t.Fatalf("Progress called for %d rounds; want 2", len(rounds))
+	}
+	// Playing by the model fit to random games beats playing at random.
+	if rounds[1].MeanScore <= rounds[0].MeanScore {
+		t.Errorf("round 2 scored %v on average; want more than the %v of random play", rounds[1].MeanScore, rounds[0].MeanScore)
+	}
+	if v := m.Value(scoring.Scorecard{}); math.IsNaN(v) || v <= 0 {
+		t.Errorf("trained model values an empty scorecard at %v; want more than 0", v)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Train(ctx, rand.New(rand.NewPCG(5, 6)), TrainOptions{Games: 20, Rounds: 2}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Train() after cancelling returned error %v; want %v", err, context.Canceled)
+	}
+}